@@ -1,14 +1,35 @@
 package audio
 
 const (
-	frameSeqPeriod = 4194304 / 512   // 512Hz
-	samplerPeriod  = 4194304 / 44100 // 44100 Hz
+	frameSeqPeriod = 4194304 / 512 // 512Hz
+
+	// DefaultSampleRate is the number of samples per second produced unless another rate is chosen
+	DefaultSampleRate = 44100
+
+	// DefaultVolume is the master volume unless another is chosen
+	DefaultVolume = 0.6
 )
 
 // Audio stream
 type Audio struct {
 	l             chan float32
 	r             chan float32
+	record        func(left, right float32)
+	stems         func(channels [4][2]float32)
+	mixedStems    bool
+	sampleRate    int
+	phase         uint32
+	sample        uint32
+	left          bandLimiter
+	right         bandLimiter
+	stemLimiters  [4][2]bandLimiter
+	volume        float32
+	muted         [4]bool
+	soloed        [4]bool
+	audible       [4]bool
+	chargeFactor  float64
+	highPass      [2]highPass
+	stemHighPass  [4][2]highPass
 	ch1           *square
 	ch2           *square
 	ch3           *wave
@@ -18,8 +39,12 @@ type Audio struct {
 	frameSeqTicks uint64
 }
 
-// NewAudio initializes our internal channel for audio data
-func New(l, r chan float32) *Audio {
+// NewAudio initializes our internal channel for audio data. Samples are produced at the given rate
+// or DefaultSampleRate when it's zero.
+func New(l, r chan float32, sampleRate int) *Audio {
+	if sampleRate == 0 {
+		sampleRate = DefaultSampleRate
+	}
 	audio := Audio{
 		l:   l,
 		r:   r,
@@ -28,9 +53,12 @@ func New(l, r chan float32) *Audio {
 		ch3: &wave{
 			waveram: [16]uint8{0x84, 0x40, 0x43, 0xAA, 0x2D, 0x78, 0x92, 0x3C, 0x60, 0x59, 0x59, 0xB0, 0x34, 0xB8, 0x2E, 0xDA},
 		},
-		ch4:     &noise{},
-		control: &control{},
-		ticks:   1,
+		ch4:        &noise{},
+		control:    &control{},
+		ticks:      1,
+		sampleRate: sampleRate,
+		volume:     DefaultVolume,
+		audible:    [4]bool{true, true, true, true},
 	}
 
 	// Set default values for the NR registers
@@ -56,6 +84,15 @@ func New(l, r chan float32) *Audio {
 	return &audio
 }
 
+// PowerOn resets the APU to its state before the boot ROM runs
+func (a *Audio) PowerOn() {
+	a.WriteNR11(0x00)
+	a.WriteNR21(0x00)
+	a.WriteNR31(0x00)
+	a.WriteNR41(0x00)
+	a.WriteNR52(0x00)
+}
+
 // EndMachineCycle emulates the audio hardware at the end of a machine cycle
 func (a *Audio) EndMachineCycle() {
 	// Each machine cycle is four clock cycles
@@ -69,6 +106,102 @@ func (a *Audio) EndMachineCycle() {
 	a.ch4.triggered = false
 }
 
+// Silence feeds the speakers with silence lasting the given number of machine
+// cycles without advancing the APU. This keeps the emulator running at the
+// correct speed while the APU isn't being emulated e.g. when rewinding.
+func (a *Audio) Silence(machineCycles int) {
+	if !a.outputting() {
+		return
+	}
+	for i := 0; i < machineCycles*4; i++ {
+		a.synthesize([4]float32{}, 0, 0)
+	}
+}
+
+// Record calls the function with every stereo sample the APU produces, which happens even when
+// there are no speakers. Passing nil stops recording.
+func (a *Audio) Record(record func(left, right float32)) {
+	a.record = record
+}
+
+// RecordStems calls the function with each channel's left and right sample whenever the APU
+// produces a sample. When mixed is true the NR51 panning and NR50 volume are applied as they are in
+// the mix, which the stems then add up to. Otherwise both sides get half the channel's raw output,
+// which keeps it between 0 and 1. Passing nil stops recording.
+func (a *Audio) RecordStems(stems func(channels [4][2]float32), mixed bool) {
+	a.stems = stems
+	a.mixedStems = mixed
+	a.stemLimiters = [4][2]bandLimiter{}
+	a.stemHighPass = [4][2]highPass{}
+}
+
+// SetVolume sets the master volume applied to the mix, from 0 to 1
+func (a *Audio) SetVolume(volume float32) {
+	a.volume = volume
+}
+
+// Volume returns the master volume
+func (a *Audio) Volume() float32 {
+	return a.volume
+}
+
+// Mute silences a channel, numbered from 1 to 4, or brings it back
+func (a *Audio) Mute(channel int, muted bool) {
+	a.muted[channel-1] = muted
+	a.updateAudible()
+}
+
+// Muted returns true if the channel is muted
+func (a *Audio) Muted(channel int) bool {
+	return a.muted[channel-1]
+}
+
+// Solo picks out a channel, numbered from 1 to 4, or stops picking it out. While any channel is
+// soloed only the soloed channels are heard.
+func (a *Audio) Solo(channel int, soloed bool) {
+	a.soloed[channel-1] = soloed
+	a.updateAudible()
+}
+
+// Soloed returns true if the channel is soloed
+func (a *Audio) Soloed(channel int) bool {
+	return a.soloed[channel-1]
+}
+
+// updateAudible works out which channels are heard after a change to the mutes or solos
+func (a *Audio) updateAudible() {
+	solo := a.soloed != [4]bool{}
+	for i := range a.audible {
+		a.audible[i] = !a.muted[i] && (!solo || a.soloed[i])
+	}
+}
+
+// SampleRate returns the number of samples the APU produces per second
+func (a *Audio) SampleRate() int {
+	return a.sampleRate
+}
+
+// speakers returns true if samples are played through speakers
+func (a *Audio) speakers() bool {
+	return a.l != nil && a.r != nil
+}
+
+// outputting returns true if samples are played or recorded
+func (a *Audio) outputting() bool {
+	return a.speakers() || a.record != nil || a.stems != nil
+}
+
+// output sends a sample to the speakers and to any recording
+func (a *Audio) output(left, right float32) {
+	if a.speakers() {
+		a.l <- left
+		a.r <- right
+	}
+	if a.record != nil {
+		a.record(left, right)
+	}
+}
+
 func (a *Audio) tickClock() {
 	if a.ticks > 4194304 {
 		a.ticks = 1
@@ -86,9 +219,10 @@ func (a *Audio) tickClock() {
 		}
 	}
 
-	// Tick this function at 44100 Hz
-	if a.ticks%samplerPeriod == 0 {
-		a.tickSampler()
+	// Synthesize the output at the full clock rate
+	if a.outputting() {
+		waves, left, right := a.mix()
+		a.synthesize(waves, left, right)
 	}
 
 	a.ticks++
@@ -145,7 +279,3 @@ func (a *Audio) tickFrameSequencer() {
 	a.frameSeqTicks++
 
 }
-
-func (a *Audio) tickSampler() {
-	a.takeSample()
-}
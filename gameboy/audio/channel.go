@@ -1,30 +1,36 @@
 package audio
 
-func (a *Audio) takeSample() {
+// mix returns the current output of each channel along with the left and right mix
+func (a *Audio) mix() (waves [4]float32, left, right float32) {
 
-	if !a.control.on || a.l == nil || a.r == nil {
+	if !a.control.on {
 		return
 	}
 
+	// Muted channels and those left out by a solo are silent
 	var wave1, wave2, wave3, wave4 float32
 
 	// channel 1
-	wave1 = a.ch1.takeSample()
+	if a.audible[0] {
+		wave1 = a.ch1.takeSample()
+	}
 
 	// channel 2
-	wave2 = a.ch2.takeSample()
+	if a.audible[1] {
+		wave2 = a.ch2.takeSample()
+	}
 
 	// channel 3
-	wave3 = a.ch3.takeSample()
+	if a.audible[2] {
+		wave3 = a.ch3.takeSample()
+	}
 
 	// channel 4
-	wave4 = a.ch4.takeSample()
-
-	// Hardcode master volume for now
-	masterVolume := float32(0.6)
+	if a.audible[3] {
+		wave4 = a.ch4.takeSample()
+	}
 
 	// Mix left channel
-	left := float32(0)
 	if a.control.ch1Left {
 		left += wave1
 	}
@@ -38,11 +44,9 @@ func (a *Audio) takeSample() {
 		left += wave4
 	}
 	left /= 4
-	left *= float32(a.control.volumeLeft) / 8 * masterVolume
-	a.l <- left
+	left *= float32(a.control.volumeLeft) / 8 * a.volume
 
 	// Mix right channel
-	right := float32(0)
 	if a.control.ch1Right {
 		right += wave1
 	}
@@ -56,7 +60,32 @@ func (a *Audio) takeSample() {
 		right += wave4
 	}
 	right /= 4
-	right *= float32(a.control.volumeRight) / 8 * masterVolume
-	a.r <- right
+	right *= float32(a.control.volumeRight) / 8 * a.volume
 
+	waves = [4]float32{wave1, wave2, wave3, wave4}
+	return
+
+}
+
+// stemSamples returns the left and right samples for each channel, either raw or panned and scaled
+// by the volume in the same way as the mix
+func (a *Audio) stemSamples(waves [4]float32) [4][2]float32 {
+	var channels [4][2]float32
+	if !a.mixedStems {
+		for i, wave := range waves {
+			channels[i] = [2]float32{wave / 2, wave / 2}
+		}
+		return channels
+	}
+	left := [4]bool{a.control.ch1Left, a.control.ch2Left, a.control.ch3Left, a.control.ch4Left}
+	right := [4]bool{a.control.ch1Right, a.control.ch2Right, a.control.ch3Right, a.control.ch4Right}
+	for i, wave := range waves {
+		if left[i] {
+			channels[i][0] = wave / 4 * float32(a.control.volumeLeft) / 8 * a.volume
+		}
+		if right[i] {
+			channels[i][1] = wave / 4 * float32(a.control.volumeRight) / 8 * a.volume
+		}
+	}
+	return channels
 }
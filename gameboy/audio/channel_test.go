@@ -0,0 +1,142 @@
+package audio
+
+import (
+	"testing"
+)
+
+// newTestAudio returns an APU playing a square wave on channel 1 on the left only and a quieter one
+// on channel 2 on both sides
+func newTestAudio() *Audio {
+	a := New(nil, nil, 0)
+	a.WriteNR50(0x73)
+	a.WriteNR51(0x32)
+	a.WriteNR11(0x80)
+	a.WriteNR12(0xf0)
+	a.WriteNR13(0x00)
+	a.WriteNR14(0x87)
+	a.WriteNR21(0x80)
+	a.WriteNR22(0x80)
+	a.WriteNR23(0x00)
+	a.WriteNR24(0x86)
+	return a
+}
+
+func TestMixedStems(t *testing.T) {
+	a := newTestAudio()
+	var mix [][2]float32
+	var stems [][4][2]float32
+	a.Record(func(left, right float32) {
+		mix = append(mix, [2]float32{left, right})
+	})
+	a.RecordStems(func(channels [4][2]float32) {
+		stems = append(stems, channels)
+	}, true)
+	for i := 0; i < 10000; i++ {
+		a.EndMachineCycle()
+	}
+	if len(mix) == 0 || len(stems) != len(mix) {
+		t.Fatalf("expected the same number of samples in the mix and the stems but found %d and %d", len(mix), len(stems))
+	}
+	var loud bool
+	for i, sample := range mix {
+		var left, right float32
+		for _, channel := range stems[i] {
+			left += channel[0]
+			right += channel[1]
+		}
+		if diff(left, sample[0]) > 1e-5 || diff(right, sample[1]) > 1e-5 {
+			t.Fatalf("sample %d: stems add up to %v but the mix is %v", i, [2]float32{left, right}, sample)
+		}
+		if stems[i][0][1] != 0 || stems[i][2] != [2]float32{} || stems[i][3] != [2]float32{} {
+			t.Fatalf("sample %d: unexpected output from a channel that isn't panned %v", i, stems[i])
+		}
+		loud = loud || stems[i][0][0] > 0
+	}
+	if !loud {
+		t.Error("expected channel 1 to be heard on the left")
+	}
+}
+
+func TestRawStems(t *testing.T) {
+	a := newTestAudio()
+	var peaks [4]float32
+	a.RecordStems(func(channels [4][2]float32) {
+		for i, channel := range channels {
+			if channel[0] != channel[1] {
+				t.Fatalf("channel %d: expected the same raw output on both sides but found %v", i+1, channel)
+			}
+			if channel[0] > peaks[i] {
+				peaks[i] = channel[0]
+			}
+		}
+	}, false)
+	for i := 0; i < 10000; i++ {
+		a.EndMachineCycle()
+	}
+	// Raw output ignores NR50 and NR51 so it only depends on each channel's envelope volume, halved.
+	// Band-limiting overshoots each step a little.
+	expected := [4]float32{15.0 / 16, 8.0 / 16, 0, 0}
+	for i := range peaks {
+		if peaks[i] < expected[i] || peaks[i] > expected[i]*1.15 {
+			t.Errorf("channel %d: expected a peak of %f but found %f", i+1, expected[i], peaks[i])
+		}
+	}
+}
+
+func diff(a, b float32) float32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func TestMuteAndSolo(t *testing.T) {
+	tests := []struct {
+		muted, soloed [4]bool
+		expected      [2]bool
+	}{
+		{expected: [2]bool{true, true}},
+		{muted: [4]bool{true}, expected: [2]bool{false, true}},
+		{soloed: [4]bool{false, true}, expected: [2]bool{false, true}},
+		{soloed: [4]bool{true, true}, expected: [2]bool{true, true}},
+		{muted: [4]bool{false, true}, soloed: [4]bool{false, true}, expected: [2]bool{false, false}},
+		{soloed: [4]bool{false, false, true}, expected: [2]bool{false, false}},
+	}
+	for _, test := range tests {
+		a := newTestAudio()
+		for i := range test.muted {
+			a.Mute(i+1, test.muted[i])
+			a.Solo(i+1, test.soloed[i])
+		}
+		var heard [2]bool
+		a.RecordStems(func(channels [4][2]float32) {
+			heard[0] = heard[0] || channels[0][0] != 0
+			heard[1] = heard[1] || channels[1][0] != 0
+		}, false)
+		for i := 0; i < 10000; i++ {
+			a.EndMachineCycle()
+		}
+		if heard != test.expected {
+			t.Errorf("muted %v and soloed %v: expected channels 1 and 2 to be heard %v but found %v", test.muted, test.soloed, test.expected, heard)
+		}
+	}
+}
+
+func TestVolume(t *testing.T) {
+	var peaks [2]float32
+	for i, volume := range []float32{DefaultVolume, DefaultVolume / 2} {
+		a := newTestAudio()
+		a.SetVolume(volume)
+		a.Record(func(left, right float32) {
+			if left > peaks[i] {
+				peaks[i] = left
+			}
+		})
+		for i := 0; i < 10000; i++ {
+			a.EndMachineCycle()
+		}
+	}
+	if peaks[0] == 0 || diff(peaks[0], 2*peaks[1]) > 1e-5 {
+		t.Errorf("expected half the volume to halve the peak of %f but found %f", peaks[0], peaks[1])
+	}
+}
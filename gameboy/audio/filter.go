@@ -0,0 +1,55 @@
+package audio
+
+import (
+	"math"
+)
+
+// The DMG and CGB pass their output through a capacitor which blocks the DC offset of the channels'
+// DACs, leaving the output centred on zero. These are the fractions of the capacitor's charge kept on
+// each clock. The CGB's capacitor charges faster so it filters out more of the bass.
+const (
+	DMGChargeFactor = 0.999958
+	CGBChargeFactor = 0.998943
+)
+
+// highPass is the output capacitor for one signal
+type highPass struct {
+	capacitor float64
+}
+
+// filter returns the sample less the charge on the capacitor and lets the capacitor charge towards
+// the sample
+func (h *highPass) filter(sample float32, chargeFactor float64) float32 {
+	out := float64(sample) - h.capacitor
+	h.capacitor = float64(sample) - out*chargeFactor
+	return float32(out)
+}
+
+// SetHighPass emulates the output capacitor with the given charge factor per clock, usually
+// DMGChargeFactor or CGBChargeFactor. Zero leaves the output unfiltered.
+func (a *Audio) SetHighPass(chargeFactor float64) {
+	// The capacitor is only updated once per output sample so the charge factor is scaled to match
+	a.chargeFactor = 0
+	if chargeFactor > 0 {
+		a.chargeFactor = math.Pow(chargeFactor, clockSpeed/float64(a.sampleRate))
+	}
+	a.highPass = [2]highPass{}
+	a.stemHighPass = [4][2]highPass{}
+}
+
+// filterOutput passes an output sample through a capacitor. The output is disconnected while every
+// DAC is off so it's silent and the capacitor keeps its charge.
+func (a *Audio) filterOutput(h *highPass, sample float32) float32 {
+	if a.chargeFactor == 0 {
+		return sample
+	}
+	if !a.dacsEnabled() {
+		return 0
+	}
+	return h.filter(sample, a.chargeFactor)
+}
+
+// dacsEnabled returns true if any channel's DAC is on
+func (a *Audio) dacsEnabled() bool {
+	return a.ch1.dacEnabled || a.ch2.dacEnabled || a.ch3.dacEnabled || a.ch4.dacEnabled
+}
@@ -0,0 +1,44 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHighPass(t *testing.T) {
+	for _, chargeFactor := range []float64{0, DMGChargeFactor, CGBChargeFactor} {
+		a := newTestAudio()
+		a.SetHighPass(chargeFactor)
+		var sum, last float64
+		var samples int
+		a.Record(func(left, right float32) {
+			last = float64(left)
+			if a.sample > DefaultSampleRate/2 {
+				sum += float64(left)
+				samples++
+			}
+		})
+
+		// The DACs only output positive levels so without the capacitor the output is offset
+		for i := 0; i < clockSpeed/4; i++ {
+			a.EndMachineCycle()
+		}
+		mean := sum / float64(samples)
+		if chargeFactor == 0 && mean < 0.1 {
+			t.Errorf("expected a DC offset without a high-pass filter but found a mean of %f", mean)
+		}
+		if chargeFactor != 0 && math.Abs(mean) > 0.001 {
+			t.Errorf("charge factor %f: expected the output to be centred on zero but found a mean of %f", chargeFactor, mean)
+		}
+
+		// The output is disconnected once every DAC is off
+		a.WriteNR12(0x00)
+		a.WriteNR22(0x00)
+		for i := 0; i < 1000; i++ {
+			a.EndMachineCycle()
+		}
+		if math.Abs(last) > 1e-6 {
+			t.Errorf("charge factor %f: expected silence with the DACs off but found %f", chargeFactor, last)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// squareState is the serialisable form of a square channel including the sweep
+// unit, which is left zeroed for channel 2
+type squareState struct {
+	Duty             uint8
+	Length           uint8
+	InitialVolume    uint8
+	EnvelopeIncrease bool
+	EnvelopeSweep    uint8
+	Frequency        uint16
+	LengthEnable     bool
+	Enabled          bool
+	DACEnabled       bool
+	DutyIndex        uint8
+	Volume           uint8
+	Timer            uint16
+	EnvelopeTimer    uint8
+	Triggered        bool
+	SweepPeriod      uint8
+	SweepIncrease    bool
+	SweepShift       uint8
+	SweepEnabled     bool
+	SweepDescending  bool
+	SweepTimer       uint8
+	ShadowFrequency  uint16
+}
+
+// waveState is the serialisable form of the wave channel
+type waveState struct {
+	Length       uint16
+	OutputLevel  uint8
+	Frequency    uint16
+	LengthEnable bool
+	WaveRAM      [16]uint8
+	Enabled      bool
+	DACEnabled   bool
+	Timer        uint16
+	OutputShift  uint8
+	Position     uint8
+	LastAccessed uint8
+	SampleBuffer uint8
+	SampleTimer  uint8
+	Triggered    bool
+}
+
+// noiseState is the serialisable form of the noise channel
+type noiseState struct {
+	Length           uint8
+	InitialVolume    uint8
+	EnvelopeIncrease bool
+	EnvelopeSweep    uint8
+	Shift            uint8
+	LFSRWidth        uint8
+	Divisor          uint8
+	LengthEnable     bool
+	Enabled          bool
+	DACEnabled       bool
+	Volume           uint8
+	Timer            uint8
+	EnvelopeTimer    uint8
+	LFSR             uint16
+	Triggered        bool
+}
+
+// state is the serialisable form of the whole APU
+type state struct {
+	On            bool
+	NR50          uint8
+	NR51          uint8
+	Ticks         uint64
+	FrameSeqTicks uint64
+	Ch1           squareState
+	Ch2           squareState
+	Ch3           waveState
+	Ch4           noiseState
+}
+
+// SaveState writes the state of the APU and all four channels
+func (a *Audio) SaveState(w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, &state{
+		On:            a.control.on,
+		NR50:          a.ReadNR50(),
+		NR51:          a.ReadNR51(),
+		Ticks:         a.ticks,
+		FrameSeqTicks: a.frameSeqTicks,
+		Ch1:           a.ch1.state(),
+		Ch2:           a.ch2.state(),
+		Ch3:           a.ch3.state(),
+		Ch4:           a.ch4.state(),
+	})
+}
+
+// LoadState restores the state of the APU and all four channels
+func (a *Audio) LoadState(r io.Reader) error {
+	var s state
+	err := binary.Read(r, binary.LittleEndian, &s)
+	if err != nil {
+		return err
+	}
+	// NR50 and NR51 are only writable while the APU is on
+	a.control.on = true
+	a.WriteNR50(s.NR50)
+	a.WriteNR51(s.NR51)
+	a.control.on = s.On
+	a.ticks = s.Ticks
+	a.frameSeqTicks = s.FrameSeqTicks
+	a.ch1.setState(s.Ch1)
+	a.ch2.setState(s.Ch2)
+	a.ch3.setState(s.Ch3)
+	a.ch4.setState(s.Ch4)
+	return nil
+}
+
+func (s *square) state() squareState {
+	st := squareState{
+		Duty:             s.duty,
+		Length:           s.length,
+		InitialVolume:    s.initialVolume,
+		EnvelopeIncrease: s.envelopeIncrease,
+		EnvelopeSweep:    s.envelopeSweep,
+		Frequency:        s.frequency,
+		LengthEnable:     s.lengthEnable,
+		Enabled:          s.enabled,
+		DACEnabled:       s.dacEnabled,
+		DutyIndex:        s.dutyIndex,
+		Volume:           s.volume,
+		Timer:            s.timer,
+		EnvelopeTimer:    s.envelopeTimer,
+		Triggered:        s.triggered,
+	}
+	if s.sweep != nil {
+		st.SweepPeriod = s.sweepPeriod
+		st.SweepIncrease = s.sweepIncrease
+		st.SweepShift = s.sweepShift
+		st.SweepEnabled = s.sweepEnabled
+		st.SweepDescending = s.sweepDescending
+		st.SweepTimer = s.sweepTimer
+		st.ShadowFrequency = s.shadowFrequency
+	}
+	return st
+}
+
+func (s *square) setState(st squareState) {
+	s.duty = st.Duty
+	s.length = st.Length
+	s.initialVolume = st.InitialVolume
+	s.envelopeIncrease = st.EnvelopeIncrease
+	s.envelopeSweep = st.EnvelopeSweep
+	s.frequency = st.Frequency
+	s.lengthEnable = st.LengthEnable
+	s.enabled = st.Enabled
+	s.dacEnabled = st.DACEnabled
+	s.dutyIndex = st.DutyIndex
+	s.volume = st.Volume
+	s.timer = st.Timer
+	s.envelopeTimer = st.EnvelopeTimer
+	s.triggered = st.Triggered
+	if s.sweep != nil {
+		s.sweepPeriod = st.SweepPeriod
+		s.sweepIncrease = st.SweepIncrease
+		s.sweepShift = st.SweepShift
+		s.sweepEnabled = st.SweepEnabled
+		s.sweepDescending = st.SweepDescending
+		s.sweepTimer = st.SweepTimer
+		s.shadowFrequency = st.ShadowFrequency
+	}
+}
+
+func (w *wave) state() waveState {
+	return waveState{
+		Length:       w.length,
+		OutputLevel:  w.outputLevel,
+		Frequency:    w.frequency,
+		LengthEnable: w.lengthEnable,
+		WaveRAM:      w.waveram,
+		Enabled:      w.enabled,
+		DACEnabled:   w.dacEnabled,
+		Timer:        w.timer,
+		OutputShift:  w.outputShift,
+		Position:     w.position,
+		LastAccessed: w.lastAccessed,
+		SampleBuffer: w.sampleBuffer,
+		SampleTimer:  w.sampleTimer,
+		Triggered:    w.triggered,
+	}
+}
+
+func (w *wave) setState(st waveState) {
+	w.length = st.Length
+	w.outputLevel = st.OutputLevel
+	w.frequency = st.Frequency
+	w.lengthEnable = st.LengthEnable
+	w.waveram = st.WaveRAM
+	w.enabled = st.Enabled
+	w.dacEnabled = st.DACEnabled
+	w.timer = st.Timer
+	w.outputShift = st.OutputShift
+	w.position = st.Position
+	w.lastAccessed = st.LastAccessed
+	w.sampleBuffer = st.SampleBuffer
+	w.sampleTimer = st.SampleTimer
+	w.triggered = st.Triggered
+}
+
+func (n *noise) state() noiseState {
+	return noiseState{
+		Length:           n.length,
+		InitialVolume:    n.initialVolume,
+		EnvelopeIncrease: n.envelopeIncrease,
+		EnvelopeSweep:    n.envelopeSweep,
+		Shift:            n.shift,
+		LFSRWidth:        n.lfsrWidth,
+		Divisor:          n.divisor,
+		LengthEnable:     n.lengthEnable,
+		Enabled:          n.enabled,
+		DACEnabled:       n.dacEnabled,
+		Volume:           n.volume,
+		Timer:            n.timer,
+		EnvelopeTimer:    n.envelopeTimer,
+		LFSR:             n.lfsr,
+		Triggered:        n.triggered,
+	}
+}
+
+func (n *noise) setState(st noiseState) {
+	n.length = st.Length
+	n.initialVolume = st.InitialVolume
+	n.envelopeIncrease = st.EnvelopeIncrease
+	n.envelopeSweep = st.EnvelopeSweep
+	n.shift = st.Shift
+	n.lfsrWidth = st.LFSRWidth
+	n.divisor = st.Divisor
+	n.lengthEnable = st.LengthEnable
+	n.enabled = st.Enabled
+	n.dacEnabled = st.DACEnabled
+	n.volume = st.Volume
+	n.timer = st.Timer
+	n.envelopeTimer = st.EnvelopeTimer
+	n.lfsr = st.LFSR
+	n.triggered = st.Triggered
+}
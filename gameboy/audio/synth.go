@@ -0,0 +1,127 @@
+package audio
+
+import (
+	"math"
+)
+
+// The APU's output only changes in steps at the 4194304Hz clock rate. Sampling it directly at the
+// output rate aliases the harmonics of those steps back into the audible range, so instead each step
+// is drawn as a band-limited step at its exact position between output samples. Steps are added up
+// as differences between consecutive output samples, and the running total of them is the output.
+const (
+	clockSpeed   = 4194304
+	kernelTaps   = 32
+	kernelPhases = 256
+	ringSize     = 64
+	phaseShift   = 14 // Converts a position in clocks between samples to one of the kernel phases
+)
+
+// kernelCutoff is the highest frequency kept as a fraction of the output rate. The kernel's window
+// fades out frequencies above it, leaving nothing by the Nyquist frequency.
+const kernelCutoff = 0.4
+
+// kernel holds the difference each step makes to the output samples around it for each of the
+// possible positions of a step between output samples
+var kernel = makeKernel()
+
+// makeKernel samples a Blackman windowed sinc at each phase. The taps are normalised so that a step
+// always adds up to exactly its full height.
+func makeKernel() *[kernelPhases][kernelTaps]float64 {
+	var k [kernelPhases][kernelTaps]float64
+	for phase := range k {
+		offset := float64(phase) / kernelPhases
+		var sum float64
+		for tap := range k[phase] {
+			x := float64(tap-kernelTaps/2+1) - offset
+			k[phase][tap] = sinc(2*kernelCutoff*x) * blackman(x)
+			sum += k[phase][tap]
+		}
+		for tap := range k[phase] {
+			k[phase][tap] /= sum
+		}
+	}
+	return &k
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// blackman is the Blackman window spanning the kernel's taps
+func blackman(x float64) float64 {
+	if math.Abs(x) >= kernelTaps/2 {
+		return 0
+	}
+	return 0.42 + 0.5*math.Cos(2*math.Pi*x/kernelTaps) + 0.08*math.Cos(4*math.Pi*x/kernelTaps)
+}
+
+// bandLimiter turns one signal into band-limited output samples. Output samples are only read once
+// they're far enough in the past that no later step can change them, which delays the output by
+// half the kernel's width.
+type bandLimiter struct {
+	deltas [ringSize]float64
+	level  float32
+	output float64
+}
+
+// update sets the signal's level at the given phase after the start of output sample n
+func (b *bandLimiter) update(level float32, n uint32, phase uint32) {
+	if level == b.level {
+		return
+	}
+	delta := float64(level - b.level)
+	b.level = level
+	start := n - kernelTaps/2 + 1
+	for tap, weight := range kernel[phase] {
+		b.deltas[(start+uint32(tap))%ringSize] += delta * weight
+	}
+}
+
+// read returns output sample n which can no longer change
+func (b *bandLimiter) read(n uint32) float32 {
+	i := n % ringSize
+	b.output += b.deltas[i]
+	b.deltas[i] = 0
+	return float32(b.output)
+}
+
+// synthesize advances the output by one clock with the given levels, producing an output sample
+// each time a whole sample period has passed
+func (a *Audio) synthesize(waves [4]float32, left, right float32) {
+	phase := a.phase >> phaseShift
+	a.left.update(left, a.sample, phase)
+	a.right.update(right, a.sample, phase)
+	if a.stems != nil {
+		channels := a.stemSamples(waves)
+		for i := range channels {
+			a.stemLimiters[i][0].update(channels[i][0], a.sample, phase)
+			a.stemLimiters[i][1].update(channels[i][1], a.sample, phase)
+		}
+	}
+
+	// The phase counts in 1/4194304ths of an output sample so the output rate is exact
+	a.phase += uint32(a.sampleRate)
+	if a.phase < clockSpeed {
+		return
+	}
+	a.phase -= clockSpeed
+	a.sample++
+	n := a.sample - kernelTaps/2
+	a.output(a.filterOutput(&a.highPass[0], a.left.read(n)), a.filterOutput(&a.highPass[1], a.right.read(n)))
+	if a.stems != nil {
+		// Mixed stems are filtered like the mix, each through a capacitor of its own
+		var channels [4][2]float32
+		for i := range channels {
+			channels[i][0] = a.stemLimiters[i][0].read(n)
+			channels[i][1] = a.stemLimiters[i][1].read(n)
+			if a.mixedStems {
+				channels[i][0] = a.filterOutput(&a.stemHighPass[i][0], channels[i][0])
+				channels[i][1] = a.filterOutput(&a.stemHighPass[i][1], channels[i][1])
+			}
+		}
+		a.stems(channels)
+	}
+}
@@ -0,0 +1,124 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+// playSquare returns the left output while channel 1 plays a 50% square wave with the given
+// frequency register, skipping the first 4096 samples while it starts
+func playSquare(sampleRate int, frequency uint16, samples int) []float64 {
+	a := New(nil, nil, sampleRate)
+	var output []float64
+	a.Record(func(left, right float32) {
+		output = append(output, float64(left))
+	})
+	a.WriteNR50(0x77)
+	a.WriteNR51(0xff)
+	a.WriteNR11(0x80)
+	a.WriteNR12(0xf0)
+	a.WriteNR13(uint8(frequency))
+	a.WriteNR14(0x80 | uint8(frequency>>8))
+	for len(output) < samples+4096 {
+		a.EndMachineCycle()
+	}
+	return output[4096 : 4096+samples]
+}
+
+// magnitude returns the level of a frequency in the samples in decibels relative to a full scale
+// sine wave. A Blackman-Harris window keeps other frequencies from leaking into the measurement.
+func magnitude(samples []float64, sampleRate int, frequency float64) float64 {
+	var re, im, gain float64
+	n := float64(len(samples))
+	for i, sample := range samples {
+		x := 2 * math.Pi * float64(i) / n
+		w := 0.35875 - 0.48829*math.Cos(x) + 0.14128*math.Cos(2*x) - 0.01168*math.Cos(3*x)
+		angle := 2 * math.Pi * frequency * float64(i) / float64(sampleRate)
+		re += sample * w * math.Cos(angle)
+		im -= sample * w * math.Sin(angle)
+		gain += w
+	}
+	return 20 * math.Log10(2*math.Hypot(re, im)/gain)
+}
+
+// alias returns the frequency that a frequency above the Nyquist frequency folds back to
+func alias(frequency float64, sampleRate int) float64 {
+	frequency = math.Mod(frequency, float64(sampleRate))
+	if frequency > float64(sampleRate)/2 {
+		frequency = float64(sampleRate) - frequency
+	}
+	return frequency
+}
+
+func TestSquareWaveSpectrum(t *testing.T) {
+	for _, sampleRate := range []int{44100, 48000, 22050} {
+		// A square wave has odd harmonics at 1/n of the fundamental's level
+		fundamental := 131072.0 / (2048 - 2000)
+		samples := playSquare(sampleRate, 2000, 16384)
+		reference := magnitude(samples, sampleRate, fundamental)
+		if reference < -20 {
+			t.Fatalf("%dHz: expected a loud fundamental but found %.1fdB", sampleRate, reference)
+		}
+		third := magnitude(samples, sampleRate, 3*fundamental)
+		if math.Abs(third-reference+20*math.Log10(3)) > 0.5 {
+			t.Errorf("%dHz: expected the third harmonic %.1fdB below the fundamental but found %.1fdB", sampleRate, 20*math.Log10(3), reference-third)
+		}
+
+		// Harmonics above the Nyquist frequency mustn't fold back into the audible range
+		for harmonic := 1; harmonic < 99; harmonic += 2 {
+			frequency := float64(harmonic) * fundamental
+			if frequency < float64(sampleRate)/2 {
+				continue
+			}
+			folded := alias(frequency, sampleRate)
+			if folded > 0.4*float64(sampleRate) || math.Abs(math.Remainder(folded, fundamental)) < 100 {
+				// Frequencies the filter is still fading out and those close to a real harmonic
+				continue
+			}
+			level := magnitude(samples, sampleRate, folded)
+			if level-reference > -65 {
+				t.Errorf("%dHz: harmonic %d aliases to %.0fHz at %.1fdB relative to the fundamental", sampleRate, harmonic, folded, level-reference)
+			}
+		}
+	}
+}
+
+func TestSampleRate(t *testing.T) {
+	for _, sampleRate := range []int{44100, 48000, 96000, 8000} {
+		a := New(nil, nil, sampleRate)
+		var samples int
+		a.Record(func(left, right float32) {
+			samples++
+		})
+		// One second of machine cycles produces exactly a second of output with no drift
+		for i := 0; i < clockSpeed/4; i++ {
+			a.EndMachineCycle()
+		}
+		if samples != sampleRate {
+			t.Errorf("expected %d samples in a second but found %d", sampleRate, samples)
+		}
+	}
+}
+
+func TestStepLevel(t *testing.T) {
+	// Every step adds up to its full height once the band-limited ringing has settled
+	a := newTestAudio()
+	var peak, last float32
+	a.Record(func(left, right float32) {
+		if left > peak {
+			peak = left
+		}
+		last = left
+	})
+	for i := 0; i < 10000; i++ {
+		a.EndMachineCycle()
+	}
+	a.WriteNR12(0x00)
+	a.WriteNR22(0x00)
+	for i := 0; i < 1000; i++ {
+		a.EndMachineCycle()
+	}
+	if peak == 0 || math.Abs(float64(last)) > 1e-6 {
+		t.Errorf("expected silence after a peak of %f once the DACs were switched off but found %f", peak, last)
+	}
+}
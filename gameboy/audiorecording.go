@@ -0,0 +1,102 @@
+package gameboy
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/scottyw/tetromino/gameboy/wav"
+)
+
+// startAudio begins recording the mixed APU output to a 16-bit stereo WAV file. When configured each
+// channel is recorded to its own file alongside it too e.g. tetris.wav has stems from tetris-ch1.wav
+// to tetris-ch4.wav.
+func (gb *Gameboy) startAudio(filename string) error {
+	w, err := wav.Create(filename, gb.audio.SampleRate(), 2)
+	if err != nil {
+		return err
+	}
+	gb.wav = w
+	gb.wavFilename = filename
+	gb.audio.Record(func(left, right float32) {
+		w.Write(left, right)
+	})
+	if gb.config.AudioStems {
+		err = gb.startStems(filename)
+		if err != nil {
+			gb.closeAudio()
+			return err
+		}
+	}
+	return nil
+}
+
+// startStems begins recording each channel to its own WAV file. Raw stems have no panning so
+// they're recorded in mono.
+func (gb *Gameboy) startStems(filename string) error {
+	channels := 2
+	if gb.config.RawAudioStems {
+		channels = 1
+	}
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+	for i := range gb.stems {
+		w, err := wav.Create(fmt.Sprintf("%s-ch%d.wav", base, i+1), gb.audio.SampleRate(), channels)
+		if err != nil {
+			return err
+		}
+		gb.stems[i] = w
+	}
+	stems := gb.stems
+	gb.audio.RecordStems(func(samples [4][2]float32) {
+		for i, w := range stems {
+			w.Write(samples[i][:channels]...)
+		}
+	}, !gb.config.RawAudioStems)
+	return nil
+}
+
+// stopAudio finishes any audio recording in progress
+func (gb *Gameboy) stopAudio() {
+	if gb.wav == nil {
+		return
+	}
+	err := gb.closeAudio()
+	if err != nil {
+		fmt.Printf("Failed to record the audio at \"%s\" (%v)\n", gb.wavFilename, err)
+	} else {
+		fmt.Printf("Audio saved to %s\n", gb.wavFilename)
+	}
+}
+
+// closeAudio stops recording and closes the mix and any stems
+func (gb *Gameboy) closeAudio() error {
+	gb.audio.Record(nil)
+	gb.audio.RecordStems(nil, false)
+	err := gb.wav.Close()
+	for i, w := range gb.stems {
+		if w != nil {
+			if stemErr := w.Close(); err == nil {
+				err = stemErr
+			}
+			gb.stems[i] = nil
+		}
+	}
+	gb.wav = nil
+	return err
+}
+
+// toggleAudio starts or stops recording audio. Recordings started from the keyboard are named after
+// the game and the time.
+func (gb *Gameboy) toggleAudio() {
+	if gb.wav != nil {
+		gb.stopAudio()
+		return
+	}
+	filename := gb.timestampedFilename(".wav")
+	err := gb.startAudio(filename)
+	if err != nil {
+		fmt.Printf("Failed to record audio (%v)\n", err)
+		return
+	}
+	fmt.Printf("Recording audio to %s\n", filename)
+}
@@ -0,0 +1,103 @@
+package gameboy
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordAudio(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "cpu_instrs.wav")
+	gb := New(Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		RecordAudio:        filename,
+		FrameLimit:         60,
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	})
+	gb.Run(context.Background())
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 60 frames of 70224 clock cycles at 44100 samples per second
+	samples := 60 * 70224 * 44100 / 4194304
+	if len(data) != 44+samples*4 || binary.LittleEndian.Uint32(data[40:]) != uint32(samples*4) {
+		t.Errorf("expected %d stereo samples but found %d bytes", samples, len(data))
+	}
+	if binary.LittleEndian.Uint32(data[24:]) != 44100 {
+		t.Errorf("expected a sample rate of 44100Hz but found %d", binary.LittleEndian.Uint32(data[24:]))
+	}
+}
+
+func TestRecordAudioStems(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, raw := range []bool{false, true} {
+		gb := New(Config{
+			RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+			RecordAudio:        filepath.Join(dir, "cpu_instrs.wav"),
+			AudioStems:         true,
+			RawAudioStems:      raw,
+			FrameLimit:         10,
+			DisableVideoOutput: true,
+			DisableAudioOutput: true,
+		})
+		gb.Run(context.Background())
+		mix, err := ioutil.ReadFile(filepath.Join(dir, "cpu_instrs.wav"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		channels := 2
+		if raw {
+			channels = 1
+		}
+		for i := 1; i <= 4; i++ {
+			stem, err := ioutil.ReadFile(filepath.Join(dir, fmt.Sprintf("cpu_instrs-ch%d.wav", i)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if binary.LittleEndian.Uint16(stem[22:]) != uint16(channels) || len(stem)-44 != (len(mix)-44)/2*channels {
+				t.Errorf("raw %v, channel %d: expected %d channels and %d bytes of samples but found %d and %d", raw, i, channels, (len(mix)-44)/2*channels, binary.LittleEndian.Uint16(stem[22:]), len(stem)-44)
+			}
+		}
+	}
+}
+
+func TestRecordAudioSampleRate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "cpu_instrs.wav")
+	gb := New(Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		RecordAudio:        filename,
+		SampleRate:         22050,
+		FrameLimit:         60,
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	})
+	gb.Run(context.Background())
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := 60 * 70224 * 22050 / 4194304
+	if binary.LittleEndian.Uint32(data[24:]) != 22050 || binary.LittleEndian.Uint32(data[40:]) != uint32(samples*4) {
+		t.Errorf("expected %d samples at 22050Hz but found %d bytes at %dHz", samples, binary.LittleEndian.Uint32(data[40:]), binary.LittleEndian.Uint32(data[24:]))
+	}
+}
@@ -10,13 +10,19 @@ import (
 )
 
 func runBlarggTest(t *testing.T, filename string, checkRAM bool) {
-	serialWriter := &bytes.Buffer{}
-	config := Config{
+	// Some of these ROMs test DMG-only behaviour like the OAM bug
+	runBlarggTestWithConfig(t, Config{
 		RomFilename:        filename,
+		ForceDMG:           true,
 		DisableVideoOutput: true,
 		DisableAudioOutput: true,
-		SerialWriter:       serialWriter,
-	}
+	}, checkRAM)
+}
+
+func runBlarggTestWithConfig(t *testing.T, config Config, checkRAM bool) {
+	filename := config.RomFilename
+	serialWriter := &bytes.Buffer{}
+	config.SerialWriter = serialWriter
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
 	gameboy := New(config)
@@ -49,6 +55,9 @@ func runBlarggTest(t *testing.T, filename string, checkRAM bool) {
 			strings.TrimPrefix(filename, "testdata/blargg/"),
 			"/", "_", -1),
 	)
+	if !config.ForceDMG {
+		screenshotFilename = strings.TrimSuffix(screenshotFilename, ".png") + "_cgb.png"
+	}
 	gameboy.ppu.Screenshot(screenshotFilename)
 	if !strings.Contains(result, "Passed") {
 		t.Errorf("\n--------\n%s\n--------\n%s\n--------\n", filename, result)
@@ -62,6 +71,14 @@ func TestBlarggCPUInstrs(t *testing.T) {
 	runBlarggTest(t, "testdata/blargg/cpu_instrs/cpu_instrs.gb", false)
 }
 
+func TestBlarggCPUInstrsCGB(t *testing.T) {
+	runBlarggTestWithConfig(t, Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	}, false)
+}
+
 func TestBlarggDMGSound(t *testing.T) {
 	runBlarggTest(t, "testdata/blargg/dmg_sound/dmg_sound.gb", true)
 }
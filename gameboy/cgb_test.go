@@ -0,0 +1,206 @@
+package gameboy
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func newCGB(t *testing.T) *Gameboy {
+	gb := New(Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	})
+	if gb.mapper.Read(0xff70) == 0xff {
+		t.Fatal("expected the cartridge to run in CGB mode")
+	}
+	return gb
+}
+
+func TestCGBWorkRAMBanks(t *testing.T) {
+	gb := newCGB(t)
+	for bank := uint8(1); bank < 8; bank++ {
+		gb.mapper.Write(0xff70, bank)
+		gb.mapper.Write(0xd000, bank)
+	}
+	gb.mapper.Write(0xff70, 0)
+	if gb.mapper.Read(0xd000) != 1 {
+		t.Errorf("expected bank 0 to select bank 1 but read %d", gb.mapper.Read(0xd000))
+	}
+	for bank := uint8(1); bank < 8; bank++ {
+		gb.mapper.Write(0xff70, bank)
+		if gb.mapper.Read(0xd000) != bank || gb.mapper.Read(0xf000) != bank {
+			t.Errorf("bank %d: read 0x%02x and echo 0x%02x", bank, gb.mapper.Read(0xd000), gb.mapper.Read(0xf000))
+		}
+	}
+}
+
+func TestCGBVideoRAMBanks(t *testing.T) {
+	gb := newCGB(t)
+	gb.mapper.Write(0xff4f, 0)
+	gb.mapper.Write(0x8000, 0x12)
+	gb.mapper.Write(0xff4f, 1)
+	gb.mapper.Write(0x8000, 0x34)
+	if gb.mapper.Read(0xff4f) != 0xff {
+		t.Errorf("expected VBK to read 0xff but read 0x%02x", gb.mapper.Read(0xff4f))
+	}
+	gb.mapper.Write(0xff4f, 0)
+	if gb.mapper.Read(0x8000) != 0x12 || gb.mapper.Read(0xff4f) != 0xfe {
+		t.Errorf("expected bank 0 to be unchanged but read 0x%02x", gb.mapper.Read(0x8000))
+	}
+}
+
+func TestCGBPaletteRAM(t *testing.T) {
+	gb := newCGB(t)
+	// Auto-increment from index 0x3e wraps back to 0
+	gb.mapper.Write(0xff68, 0xbe)
+	for _, value := range []uint8{0x1f, 0x00, 0xe0, 0x03} {
+		gb.mapper.Write(0xff69, value)
+	}
+	if gb.mapper.Read(0xff68) != 0xc2 {
+		t.Errorf("expected BCPS 0xc2 but read 0x%02x", gb.mapper.Read(0xff68))
+	}
+	gb.mapper.Write(0xff68, 0x00)
+	if gb.mapper.Read(0xff69) != 0xe0 {
+		t.Errorf("expected BCPD 0xe0 but read 0x%02x", gb.mapper.Read(0xff69))
+	}
+	gb.mapper.Write(0xff68, 0x3f)
+	if gb.mapper.Read(0xff69) != 0x00 {
+		t.Errorf("expected BCPD 0x00 but read 0x%02x", gb.mapper.Read(0xff69))
+	}
+}
+
+func TestCGBGeneralPurposeDMA(t *testing.T) {
+	gb := newCGB(t)
+	for i := uint16(0); i < 0x20; i++ {
+		gb.mapper.Write(0xc100+i, uint8(i))
+	}
+	gb.mapper.Write(0xff51, 0xc1)
+	gb.mapper.Write(0xff52, 0x00)
+	gb.mapper.Write(0xff53, 0x02)
+	gb.mapper.Write(0xff54, 0x00)
+	gb.mapper.Write(0xff55, 0x01)
+	if gb.mapper.Read(0xff55) != 0xff {
+		t.Errorf("expected HDMA5 0xff after the transfer but read 0x%02x", gb.mapper.Read(0xff55))
+	}
+	for i := uint16(0); i < 0x20; i++ {
+		if gb.mapper.Read(0x8200+i) != uint8(i) {
+			t.Errorf("expected 0x%02x at 0x%04x but read 0x%02x", i, 0x8200+i, gb.mapper.Read(0x8200+i))
+		}
+	}
+}
+
+func TestCGBGeneralPurposeDMAStall(t *testing.T) {
+	for _, doubleSpeed := range []bool{false, true} {
+		gb := newCGB(t)
+		if doubleSpeed {
+			gb.mapper.SwitchSpeed()
+		}
+		gb.mapper.Write(0xff51, 0xc0)
+		gb.mapper.Write(0xff52, 0x00)
+		gb.mapper.Write(0xff53, 0x00)
+		gb.mapper.Write(0xff54, 0x00)
+		gb.mapper.Write(0xff55, 0x02)
+		// Three blocks of 0x10 bytes stall the CPU for 8 machine cycles each at normal speed, and the
+		// CPU runs two machine cycles for every one of the PPU's in double speed mode
+		expected := 3*8 - 1
+		if doubleSpeed {
+			expected = 3*16 - 2
+		}
+		gb.runMachineCycle(0)
+		stalled := 0
+		for gb.mapper.StallCPU() {
+			stalled++
+		}
+		if stalled != expected {
+			t.Errorf("double speed %v: expected the CPU to stall for %d machine cycles but it stalled for %d", doubleSpeed, expected, stalled)
+		}
+	}
+}
+
+func TestCGBHBlankDMA(t *testing.T) {
+	gb := newCGB(t)
+	for i := uint16(0); i < 0x30; i++ {
+		gb.mapper.Write(0xc000+i, uint8(i)+1)
+	}
+	gb.mapper.Write(0xff51, 0xc0)
+	gb.mapper.Write(0xff52, 0x00)
+	gb.mapper.Write(0xff53, 0x00)
+	gb.mapper.Write(0xff54, 0x00)
+	gb.mapper.Write(0xff55, 0x82)
+	if gb.mapper.Read(0xff55) != 0x02 {
+		t.Errorf("expected HDMA5 0x02 while active but read 0x%02x", gb.mapper.Read(0xff55))
+	}
+	// Each line has one h-blank so run a line at a time
+	for line := 0; line < 3; line++ {
+		for mtick := 0; mtick < 114; mtick++ {
+			gb.ppu.EndMachineCycle()
+			gb.mapper.EndMachineCycle()
+		}
+	}
+	if gb.mapper.Read(0xff55) != 0xff {
+		t.Errorf("expected HDMA5 0xff after the transfer but read 0x%02x", gb.mapper.Read(0xff55))
+	}
+	if gb.mapper.Read(0x802f) != 0x30 {
+		t.Errorf("expected 0x30 at 0x802f but read 0x%02x", gb.mapper.Read(0x802f))
+	}
+}
+
+func TestCGBSpeedSwitch(t *testing.T) {
+	gb := newCGB(t)
+	gb.mapper.Write(0xff4d, 0x01)
+	if gb.mapper.Read(0xff4d) != 0x7f {
+		t.Errorf("expected KEY1 0x7f but read 0x%02x", gb.mapper.Read(0xff4d))
+	}
+	gb.mapper.SwitchSpeed()
+	if !gb.mapper.DoubleSpeed() || gb.mapper.Read(0xff4d) != 0xfe {
+		t.Errorf("expected double speed with KEY1 0xfe but read 0x%02x", gb.mapper.Read(0xff4d))
+	}
+	if gb.mapper.Read(0xff04) != 0x00 {
+		t.Errorf("expected the speed switch to reset DIV but read 0x%02x", gb.mapper.Read(0xff04))
+	}
+}
+
+func TestDMGHidesCGBRegisters(t *testing.T) {
+	gb := New(Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		ForceDMG:           true,
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	})
+	for _, addr := range []uint16{0xff4d, 0xff4f, 0xff55, 0xff68, 0xff69, 0xff70} {
+		if gb.mapper.Read(addr) != 0xff {
+			t.Errorf("expected 0xff at 0x%04x but read 0x%02x", addr, gb.mapper.Read(addr))
+		}
+	}
+}
+
+func TestForceDMGIgnoredForCGBOnly(t *testing.T) {
+	rom, err := ioutil.ReadFile("testdata/blargg/cpu_instrs/cpu_instrs.gb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rom[0x143] = 0xc0
+	f, err := ioutil.TempFile("", "cgb-only-*.gbc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(rom)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A cartridge that only runs on a CGB stays in CGB mode
+	gb := New(Config{
+		RomFilename:        f.Name(),
+		ForceDMG:           true,
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	})
+	if gb.mapper.Read(0xff70) == 0xff {
+		t.Error("expected a CGB-only cartridge to ignore ForceDMG")
+	}
+}
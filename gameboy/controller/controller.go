@@ -28,41 +28,140 @@ type Action int
 const (
 	// TakeScreenshot of the current LCD
 	TakeScreenshot Action = iota
+	// SaveState of the whole machine to a numbered slot
+	SaveState
+	// LoadState of the whole machine from a numbered slot
+	LoadState
+	// StartRewind plays the game backwards until StopRewind
+	StartRewind
+	// StopRewind resumes normal play
+	StopRewind
+	// NextPalette switches the colours used for DMG games
+	NextPalette
+	// ToggleVideo starts or stops recording frames to an animation
+	ToggleVideo
+	// ToggleAudio starts or stops recording audio to a WAV file
+	ToggleAudio
+	// ToggleMute silences or restores a numbered audio channel
+	ToggleMute
+	// ToggleSolo picks out a numbered audio channel or stops picking it out
+	ToggleSolo
 )
 
+// mltReq is the SGB command which enables multiple joypads
+const mltReq = 0x11
+
 type Controller struct {
 	joyp           uint8
-	directionInput uint8
-	buttonInput    uint8
+	directionInput [4]uint8
+	buttonInput    [4]uint8
+
+	// SGB command packets are sent one bit at a time by pulsing P14 and P15
+	onCommand func([]byte)
+	packet    [16]byte
+	bits      int
+	command   []byte
+	players   uint8
+	player    uint8
 }
 
 func New() *Controller {
 	return &Controller{
 		joyp:           0x0f,
-		directionInput: 0x0f,
-		buttonInput:    0x0f,
+		directionInput: [4]uint8{0x0f, 0x0f, 0x0f, 0x0f},
+		buttonInput:    [4]uint8{0x0f, 0x0f, 0x0f, 0x0f},
+		bits:           -1,
+		players:        1,
 	}
 }
 
+// EnableSGB decodes SGB command packets written to JOYP, passing each complete command to onCommand
+func (c *Controller) EnableSGB(onCommand func([]byte)) {
+	c.onCommand = onCommand
+}
+
 func (c *Controller) ReadJOYP() uint8 {
 	// Bit 5 - P15 Select Button Keys      (0=Select)
 	// Bit 4 - P14 Select Direction Keys   (0=Select)
 	// First two bits are always high
 	if c.joyp&0x10 == 0 {
-		return c.joyp&0xf0 | c.directionInput&0x0f | 0xc0
+		return c.joyp&0xf0 | c.directionInput[c.player]&0x0f | 0xc0
 	}
 	if c.joyp&0x20 == 0 {
-		return c.joyp&0xf0 | c.buttonInput&0x0f | 0xc0
+		return c.joyp&0xf0 | c.buttonInput[c.player]&0x0f | 0xc0
+	}
+	// With multiple SGB joypads the low bits identify the current joypad (0xf is joypad 1)
+	if c.players > 1 {
+		return c.joyp&0xf0 | (0x0f - c.player) | 0xc0
 	}
 	return c.joyp | 0xcf
 }
 
 func (c *Controller) WriteJOYP(value uint8) {
+	previous := c.joyp
 	c.joyp = value
+	if c.onCommand == nil {
+		return
+	}
+	switch value & 0x30 {
+	case 0x00:
+		// Pulling both lines low resets the SGB ready for a new packet
+		c.packet = [16]byte{}
+		c.bits = 0
+	case 0x10, 0x20:
+		// Each bit is a pulse on P15 for a one or P14 for a zero
+		if c.bits < 0 || previous&0x30 != 0x30 {
+			return
+		}
+		if c.bits < 128 {
+			if value&0x30 == 0x10 {
+				c.packet[c.bits/8] |= 1 << uint(c.bits%8)
+			}
+			c.bits++
+			return
+		}
+		// The zero stop bit ends the packet
+		c.bits = -1
+		c.receivePacket()
+	case 0x30:
+		// The next joypad is selected when P15 goes high
+		if c.players > 1 && c.bits < 0 && previous&0x20 == 0 {
+			c.player = (c.player + 1) % c.players
+		}
+	}
+}
+
+// receivePacket adds a packet to the current command. The first packet holds the command number
+// in bits 3-7 of its first byte and the number of packets in bits 0-2.
+func (c *Controller) receivePacket() {
+	c.command = append(c.command, c.packet[:]...)
+	length := int(c.command[0] & 0x07)
+	if length == 0 {
+		length = 1
+	}
+	if len(c.command) < length*16 {
+		return
+	}
+	command := c.command
+	c.command = nil
+	if command[0]>>3 == mltReq {
+		c.players = [4]uint8{1, 2, 1, 4}[command[1]&0x03]
+		c.player = 0
+	}
+	c.onCommand(command)
 }
 
 // ButtonAction turns UI key presses into emulator button presses corresponding to the Gameboy controls
 func (c *Controller) ButtonAction(button Button, pressed bool) {
+	c.PlayerButtonAction(0, button, pressed)
+}
+
+// PlayerButtonAction presses buttons on one of the four joypads that an SGB supports. Presses on
+// joypads the game hasn't enabled with MLT_REQ are ignored.
+func (c *Controller) PlayerButtonAction(player int, button Button, pressed bool) {
+	if player < 0 || player >= int(c.players) {
+		return
+	}
 
 	// Bit 3 - P13 Input Down  or Start    (0=Pressed) (Read Only)
 	// Bit 2 - P12 Input Up    or Select   (0=Pressed) (Read Only)
@@ -73,62 +172,62 @@ func (c *Controller) ButtonAction(button Button, pressed bool) {
 
 	case Start:
 		if pressed {
-			c.buttonInput &^= 0x8
+			c.buttonInput[player] &^= 0x8
 		} else {
-			c.buttonInput |= 0x8
+			c.buttonInput[player] |= 0x8
 		}
 
 	case Select:
 		if pressed {
-			c.buttonInput &^= 0x4
+			c.buttonInput[player] &^= 0x4
 		} else {
-			c.buttonInput |= 0x4
+			c.buttonInput[player] |= 0x4
 		}
 
 	case B:
 		if pressed {
-			c.buttonInput &^= 0x2
+			c.buttonInput[player] &^= 0x2
 		} else {
-			c.buttonInput |= 0x2
+			c.buttonInput[player] |= 0x2
 		}
 
 	case A:
 		if pressed {
-			c.buttonInput &^= 0x1
+			c.buttonInput[player] &^= 0x1
 		} else {
-			c.buttonInput |= 0x1
+			c.buttonInput[player] |= 0x1
 		}
 
 	case Down:
 		if pressed {
-			c.directionInput &^= 0x8
-			c.directionInput |= 0x4 // Unpress up
+			c.directionInput[player] &^= 0x8
+			c.directionInput[player] |= 0x4 // Unpress up
 		} else {
-			c.directionInput |= 0x8
+			c.directionInput[player] |= 0x8
 		}
 
 	case Up:
 		if pressed {
-			c.directionInput &^= 0x4
-			c.directionInput |= 0x8 // Unpress down
+			c.directionInput[player] &^= 0x4
+			c.directionInput[player] |= 0x8 // Unpress down
 		} else {
-			c.directionInput |= 0x4
+			c.directionInput[player] |= 0x4
 		}
 
 	case Left:
 		if pressed {
-			c.directionInput &^= 0x2
-			c.directionInput |= 0x1 // Unpress right
+			c.directionInput[player] &^= 0x2
+			c.directionInput[player] |= 0x1 // Unpress right
 		} else {
-			c.directionInput |= 0x2
+			c.directionInput[player] |= 0x2
 		}
 
 	case Right:
 		if pressed {
-			c.directionInput &^= 0x1
-			c.directionInput |= 0x2 // Unpress left
+			c.directionInput[player] &^= 0x1
+			c.directionInput[player] |= 0x2 // Unpress left
 		} else {
-			c.directionInput |= 0x1
+			c.directionInput[player] |= 0x1
 		}
 	}
 }
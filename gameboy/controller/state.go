@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// state is the serialisable form of the controller
+type state struct {
+	JOYP           uint8
+	DirectionInput [4]uint8
+	ButtonInput    [4]uint8
+	Packet         [16]byte
+	Bits           int16
+	CommandLength  uint8
+	Command        [112]byte
+	Players        uint8
+	Player         uint8
+}
+
+// SaveState writes the controller state
+func (c *Controller) SaveState(w io.Writer) error {
+	s := state{
+		JOYP:           c.joyp,
+		DirectionInput: c.directionInput,
+		ButtonInput:    c.buttonInput,
+		Packet:         c.packet,
+		Bits:           int16(c.bits),
+		CommandLength:  uint8(len(c.command)),
+		Players:        c.players,
+		Player:         c.player,
+	}
+	copy(s.Command[:], c.command)
+	return binary.Write(w, binary.LittleEndian, &s)
+}
+
+// LoadState restores the controller state
+func (c *Controller) LoadState(r io.Reader) error {
+	var s state
+	err := binary.Read(r, binary.LittleEndian, &s)
+	if err != nil {
+		return err
+	}
+	c.joyp = s.JOYP
+	c.directionInput = s.DirectionInput
+	c.buttonInput = s.ButtonInput
+	c.packet = s.Packet
+	c.bits = int(s.Bits)
+	c.command = nil
+	if s.CommandLength > 0 {
+		c.command = append(c.command, s.Command[:s.CommandLength]...)
+	}
+	c.players = s.Players
+	c.player = s.Player
+	return nil
+}
@@ -24,6 +24,18 @@ const (
 
 var bits = [8]uint8{bit0, bit1, bit2, bit3, bit4, bit5, bit6, bit7}
 
+// sequence identifies the list of subinstructions the CPU is currently executing
+type sequence uint8
+
+const (
+	noSequence sequence = iota
+	normalSequence
+	prefixSequence
+	veryShortInterruptSequence
+	shortInterruptSequence
+	longInterruptSequence
+)
+
 // CPU stores the internal CPU state
 type CPU struct {
 	// 8-bit registers
@@ -46,11 +58,20 @@ type CPU struct {
 	oam                    *oam.OAM
 	mapper                 *memory.Mapper
 	currentInstruction     uint8
+	currentSequence        sequence
 	currentSubinstructions []func()
 	currentCycle           int
 	currentIsFinishedEarly func(int) bool
 	currentMetadata        *metadata
 
+	// Dispatch tables of subinstructions bound to this CPU
+	normal             [256][]func()
+	prefix             [256][]func()
+	veryShortInterrupt []func()
+	shortInterrupt     []func()
+	longInterrupt      []func()
+	isFinishedEarlys   [256]func(int) bool
+
 	// Context
 	u8a uint8 // 8-bit instruction argument
 	u8b uint8 // Additional 8-bit instruction argument
@@ -63,7 +84,25 @@ type CPU struct {
 }
 
 // NewCPU returns a CPU initialized as a Gameboy does on start
-func New(interrupts *interrupts.Interrupts, oam *oam.OAM, debugCPU bool, mapper *memory.Mapper) *CPU {
+func New(interrupts *interrupts.Interrupts, oam *oam.OAM, cgb, debugCPU bool, mapper *memory.Mapper) *CPU {
+	if cgb {
+		return &CPU{
+			interrupts: interrupts,
+			oam:        oam,
+			mapper:     mapper,
+			debugCPU:   debugCPU,
+			a:          0x11,
+			f:          0x80,
+			b:          0x00,
+			c:          0x00,
+			d:          0xff,
+			e:          0x56,
+			h:          0x00,
+			l:          0x0d,
+			sp:         0xfffe,
+			pc:         0x0100,
+		}
+	}
 	return &CPU{
 		interrupts: interrupts,
 		oam:        oam,
@@ -82,6 +121,13 @@ func New(interrupts *interrupts.Interrupts, oam *oam.OAM, debugCPU bool, mapper
 	}
 }
 
+// PowerOn resets the CPU to its state before the boot ROM runs
+func (cpu *CPU) PowerOn() {
+	cpu.a, cpu.f, cpu.b, cpu.c, cpu.d, cpu.e, cpu.h, cpu.l = 0, 0, 0, 0, 0, 0, 0, 0
+	cpu.sp = 0x0000
+	cpu.pc = 0x0000
+}
+
 // Restart the CPU again on button press
 func (cpu *CPU) OnInput() {
 	cpu.stopped = false
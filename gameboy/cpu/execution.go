@@ -36,31 +36,58 @@ func (cpu *CPU) handleInterrupt() {
 	}
 }
 
-func (cpu *CPU) checkInterrupts() []func() {
+func (cpu *CPU) checkInterrupts() sequence {
 	if cpu.interrupts.Pending() {
 		if cpu.interrupts.Enabled() {
 			if cpu.halted {
 				cpu.halted = false
-				return longInterrupt
+				return longInterruptSequence
 			}
-			return shortInterrupt
+			return shortInterruptSequence
 		} else {
 			if cpu.halted {
 				cpu.halted = false
-				return veryShortInterrupt
+				return veryShortInterruptSequence
 			}
 		}
 	}
-	return nil
+	return noSequence
+}
+
+// loadSequence looks up the subinstructions to be executed for a sequence
+// using the current instruction where relevant
+func (cpu *CPU) loadSequence(seq sequence) {
+	cpu.currentSequence = seq
+	switch seq {
+	case normalSequence:
+		cpu.currentMetadata = instructionMetadata[cpu.currentInstruction]
+		cpu.currentSubinstructions = cpu.normal[cpu.currentInstruction]
+		cpu.currentIsFinishedEarly = cpu.isFinishedEarlys[cpu.currentInstruction]
+	case prefixSequence:
+		cpu.currentMetadata = prefixedInstructionMetadata[cpu.currentInstruction]
+		cpu.currentSubinstructions = cpu.prefix[cpu.currentInstruction]
+		cpu.currentIsFinishedEarly = nil
+	case veryShortInterruptSequence:
+		cpu.currentSubinstructions = cpu.veryShortInterrupt
+		cpu.currentIsFinishedEarly = nil
+	case shortInterruptSequence:
+		cpu.currentSubinstructions = cpu.shortInterrupt
+		cpu.currentIsFinishedEarly = nil
+	case longInterruptSequence:
+		cpu.currentSubinstructions = cpu.longInterrupt
+		cpu.currentIsFinishedEarly = nil
+	default:
+		cpu.currentSubinstructions = nil
+		cpu.currentIsFinishedEarly = nil
+	}
 }
 
 func (cpu *CPU) next() bool {
 
 	interrupts := cpu.checkInterrupts()
-	if interrupts != nil {
+	if interrupts != noSequence {
 		cpu.currentCycle = 0
-		cpu.currentSubinstructions = interrupts
-		cpu.currentIsFinishedEarly = nil
+		cpu.loadSequence(interrupts)
 		return false
 	}
 
@@ -75,14 +102,10 @@ func (cpu *CPU) next() bool {
 		cpu.pc++
 		cpu.currentInstruction = mapper.Read(cpu.pc)
 		cpu.currentCycle = 0
-		cpu.currentMetadata = prefixedInstructionMetadata[cpu.currentInstruction]
-		cpu.currentSubinstructions = prefix[cpu.currentInstruction]
-		cpu.currentIsFinishedEarly = nil
+		cpu.loadSequence(prefixSequence)
 	} else {
 		cpu.currentCycle = 0
-		cpu.currentMetadata = instructionMetadata[cpu.currentInstruction]
-		cpu.currentSubinstructions = normal[cpu.currentInstruction]
-		cpu.currentIsFinishedEarly = isFinishedEarlys[cpu.currentInstruction]
+		cpu.loadSequence(normalSequence)
 	}
 
 	// Reset any context from previous instructions
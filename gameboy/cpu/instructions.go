@@ -988,6 +988,11 @@ func (cpu *CPU) scf() {
 }
 
 func (cpu *CPU) stop() {
+	// STOP switches speed instead of stopping when a CGB speed switch is armed
+	if cpu.mapper.SpeedSwitchRequested() {
+		cpu.mapper.SwitchSpeed()
+		return
+	}
 	cpu.stopped = true
 }
 
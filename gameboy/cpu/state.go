@@ -0,0 +1,88 @@
+package cpu
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// state is the serialisable form of the CPU. Subinstructions are closures so
+// mid-instruction progress is captured as the sequence, opcode and cycle
+// needed to look them up again on load.
+type state struct {
+	A, B, C, D, E, F, H, L uint8
+	SP                     uint16
+	PC                     uint16
+	Halted                 bool
+	Haltbug                bool
+	Stopped                bool
+	Instruction            uint8
+	Sequence               uint8
+	Cycle                  uint8
+	U8a, U8b, M8a, M8b     uint8
+	MooneyeBreakpoint      bool
+}
+
+// SaveState writes the CPU state
+func (cpu *CPU) SaveState(w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, &state{
+		A:                 cpu.a,
+		B:                 cpu.b,
+		C:                 cpu.c,
+		D:                 cpu.d,
+		E:                 cpu.e,
+		F:                 cpu.f,
+		H:                 cpu.h,
+		L:                 cpu.l,
+		SP:                cpu.sp,
+		PC:                cpu.pc,
+		Halted:            cpu.halted,
+		Haltbug:           cpu.haltbug,
+		Stopped:           cpu.stopped,
+		Instruction:       cpu.currentInstruction,
+		Sequence:          uint8(cpu.currentSequence),
+		Cycle:             uint8(cpu.currentCycle),
+		U8a:               cpu.u8a,
+		U8b:               cpu.u8b,
+		M8a:               cpu.m8a,
+		M8b:               cpu.m8b,
+		MooneyeBreakpoint: cpu.mooneyeDebugBreakpoint,
+	})
+}
+
+// LoadState restores the CPU state
+func (cpu *CPU) LoadState(r io.Reader) error {
+	var s state
+	err := binary.Read(r, binary.LittleEndian, &s)
+	if err != nil {
+		return err
+	}
+	if sequence(s.Sequence) > longInterruptSequence {
+		return fmt.Errorf("invalid CPU sequence: %d", s.Sequence)
+	}
+	cpu.a = s.A
+	cpu.b = s.B
+	cpu.c = s.C
+	cpu.d = s.D
+	cpu.e = s.E
+	cpu.f = s.F
+	cpu.h = s.H
+	cpu.l = s.L
+	cpu.sp = s.SP
+	cpu.pc = s.PC
+	cpu.halted = s.Halted
+	cpu.haltbug = s.Haltbug
+	cpu.stopped = s.Stopped
+	cpu.currentInstruction = s.Instruction
+	cpu.currentCycle = int(s.Cycle)
+	cpu.u8a = s.U8a
+	cpu.u8b = s.U8b
+	cpu.m8a = s.M8a
+	cpu.m8b = s.M8b
+	cpu.mooneyeDebugBreakpoint = s.MooneyeBreakpoint
+	cpu.loadSequence(sequence(s.Sequence))
+	if cpu.currentCycle > len(cpu.currentSubinstructions) {
+		return fmt.Errorf("invalid CPU cycle: %d", s.Cycle)
+	}
+	return nil
+}
@@ -15,27 +15,17 @@ type Display struct {
 	window *glfw.Window
 }
 
-// New implements an LCD display in GL
-func New(controller *controller.Controller, onInput func(), debug bool) *Display {
+// New implements an LCD display in GL for frames of the given size
+func New(onButton func(controller.Button, bool), onAction func(controller.Action, int), width, height int) *Display {
 
 	if err := glfw.Init(); err != nil {
 		panic(fmt.Sprintf("Failed to create display: %v", err))
 	}
 
-	var width float32
-	var height float32
-	if debug {
-		width = 256
-		height = 256
-	} else {
-		width = 160
-		height = 144
-	}
-
 	glfw.WindowHint(glfw.ContextVersionMajor, 2)
 	glfw.WindowHint(glfw.ContextVersionMinor, 1)
 	glfw.WindowHint(glfw.Resizable, 0)
-	window, err := glfw.CreateWindow(int(width*3), int(height*3), "Tetromino", nil, nil)
+	window, err := glfw.CreateWindow(width*3, height*3, "Tetromino", nil, nil)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to create display: %v", err))
 	}
@@ -48,7 +38,7 @@ func New(controller *controller.Controller, onInput func(), debug bool) *Display
 		panic(fmt.Sprintf("Failed to create display: %v", err))
 	}
 	gl.Enable(gl.TEXTURE_2D)
-	window.SetKeyCallback(onKeyFunc(controller, onInput))
+	window.SetKeyCallback(onKeyFunc(onButton, onAction))
 
 	var texture uint32
 	gl.GenTextures(1, &texture)
@@ -88,36 +78,90 @@ func (d *Display) RenderFrame(image *image.RGBA) bool {
 	return d.window.ShouldClose()
 }
 
-func onKeyFunc(c *controller.Controller, onInput func()) func(*glfw.Window, glfw.Key, int, glfw.Action, glfw.ModifierKey) {
+// Function keys F1 to F9 load state from the matching slot, or save state to it when shift is held
+var stateSlotKeys = map[glfw.Key]int{
+	glfw.KeyF1: 1,
+	glfw.KeyF2: 2,
+	glfw.KeyF3: 3,
+	glfw.KeyF4: 4,
+	glfw.KeyF5: 5,
+	glfw.KeyF6: 6,
+	glfw.KeyF7: 7,
+	glfw.KeyF8: 8,
+	glfw.KeyF9: 9,
+}
+
+var channelKeys = map[glfw.Key]int{
+	glfw.Key1: 1,
+	glfw.Key2: 2,
+	glfw.Key3: 3,
+	glfw.Key4: 4,
+}
+
+func onKeyFunc(onButton func(controller.Button, bool), onAction func(controller.Action, int)) func(*glfw.Window, glfw.Key, int, glfw.Action, glfw.ModifierKey) {
 	return func(window *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
 		if action != glfw.Press && action != glfw.Release {
 			return
 		}
+		if slot, ok := stateSlotKeys[key]; ok {
+			if action == glfw.Press {
+				if mods&glfw.ModShift > 0 {
+					onAction(controller.SaveState, slot)
+				} else {
+					onAction(controller.LoadState, slot)
+				}
+			}
+			return
+		}
+		if channel, ok := channelKeys[key]; ok {
+			if action == glfw.Press {
+				if mods&glfw.ModShift > 0 {
+					onAction(controller.ToggleSolo, channel)
+				} else {
+					onAction(controller.ToggleMute, channel)
+				}
+			}
+			return
+		}
 		switch key {
+		case glfw.KeyBackspace:
+			if action == glfw.Press {
+				onAction(controller.StartRewind, 0)
+			} else {
+				onAction(controller.StopRewind, 0)
+			}
+		case glfw.KeyT:
+			if action == glfw.Press {
+				onAction(controller.TakeScreenshot, 0)
+			}
+		case glfw.KeyR:
+			if action == glfw.Press {
+				onAction(controller.ToggleAudio, 0)
+			}
+		case glfw.KeyV:
+			if action == glfw.Press {
+				onAction(controller.ToggleVideo, 0)
+			}
+		case glfw.KeyP:
+			if action == glfw.Press {
+				onAction(controller.NextPalette, 0)
+			}
 		case glfw.KeyA:
-			c.ButtonAction(controller.Start, action == glfw.Press)
-			onInput()
+			onButton(controller.Start, action == glfw.Press)
 		case glfw.KeyS:
-			c.ButtonAction(controller.Select, action == glfw.Press)
-			onInput()
+			onButton(controller.Select, action == glfw.Press)
 		case glfw.KeyZ:
-			c.ButtonAction(controller.B, action == glfw.Press)
-			onInput()
+			onButton(controller.B, action == glfw.Press)
 		case glfw.KeyX:
-			c.ButtonAction(controller.A, action == glfw.Press)
-			onInput()
+			onButton(controller.A, action == glfw.Press)
 		case glfw.KeyUp:
-			c.ButtonAction(controller.Up, action == glfw.Press)
-			onInput()
+			onButton(controller.Up, action == glfw.Press)
 		case glfw.KeyDown:
-			c.ButtonAction(controller.Down, action == glfw.Press)
-			onInput()
+			onButton(controller.Down, action == glfw.Press)
 		case glfw.KeyLeft:
-			c.ButtonAction(controller.Left, action == glfw.Press)
-			onInput()
+			onButton(controller.Left, action == glfw.Press)
 		case glfw.KeyRight:
-			c.ButtonAction(controller.Right, action == glfw.Press)
-			onInput()
+			onButton(controller.Right, action == glfw.Press)
 		}
 	}
 }
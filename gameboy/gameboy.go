@@ -1,27 +1,64 @@
 package gameboy
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"hash/crc32"
+	"image"
 	"io"
 	"io/ioutil"
+	"time"
 
 	"github.com/scottyw/tetromino/gameboy/audio"
 	"github.com/scottyw/tetromino/gameboy/controller"
 	"github.com/scottyw/tetromino/gameboy/cpu"
 	"github.com/scottyw/tetromino/gameboy/display"
 	"github.com/scottyw/tetromino/gameboy/interrupts"
+	"github.com/scottyw/tetromino/gameboy/link"
 	"github.com/scottyw/tetromino/gameboy/memory"
 	"github.com/scottyw/tetromino/gameboy/oam"
 	"github.com/scottyw/tetromino/gameboy/ppu"
+	"github.com/scottyw/tetromino/gameboy/printer"
 	"github.com/scottyw/tetromino/gameboy/serial"
+	"github.com/scottyw/tetromino/gameboy/sgb"
 	"github.com/scottyw/tetromino/gameboy/speakers"
 	"github.com/scottyw/tetromino/gameboy/timer"
+	"github.com/scottyw/tetromino/gameboy/video"
+	"github.com/scottyw/tetromino/gameboy/wav"
 )
 
 // Config control emulator behaviour
 type Config struct {
 	RomFilename        string
+	BootROM            bool          // Run the embedded DMG boot ROM before the cartridge
+	BootROMFilename    string        // Run this boot ROM before the cartridge instead of the embedded one
+	ForceDMG           bool          // Run CGB cartridges on DMG hardware where the cartridge allows it
+	SGB                bool          // Run as a Super Game Boy with colour palettes, borders and multiple joypads
+	SaveFilename       string        // Battery-backed cart RAM is persisted here (disabled when empty)
+	RewindBudget       int           // Bytes of memory used to hold rewind history (disabled when zero)
+	RecordMovie        string        // Button presses are recorded to this movie file (disabled when empty)
+	PlayMovie          string        // Button presses are played back from this movie file (disabled when empty)
+	LinkListen         string        // Wait for another tetromino to connect a link cable to this address
+	LinkConnect        string        // Connect a link cable to another tetromino listening at this address
+	PrinterDirectory   string        // Plug a Game Boy Printer into the serial port that prints to PNG files here
+	AllowVRAMAccess    bool          // Let the CPU access VRAM and OAM while the PPU is using them, for debugging
+	Palette            string        // Colours for DMG games as a built-in palette name or 4 hex colours
+	PaletteFile        string        // Colours for DMG games read from a palette file (overrides Palette)
+	ScreenshotDir      string        // Screenshots and recordings started from the keyboard are written here (the current directory when empty)
+	ScreenshotScale    int           // Screenshots are enlarged by this factor (actual size when zero)
+	ScreenshotRaw      bool          // Screenshots show only the 160x144 LCD without the SGB border or debug frame
+	RecordVideo        string        // Frames are recorded to this animated GIF or PNG file (disabled when empty)
+	VideoScale         int           // Recorded frames are enlarged by this factor (actual size when zero)
+	FrameLimit         int           // The emulator stops after running this many frames (unlimited when zero)
+	RecordAudio        string        // Audio is recorded to this WAV file, even without speakers (disabled when empty)
+	AudioStems         bool          // Each channel is recorded to its own WAV file alongside the audio recording
+	RawAudioStems      bool          // Channel recordings leave out the NR51 panning and NR50 volume
+	SampleRate         int           // Audio samples per second (44100 when zero)
+	AudioBufferSize    int           // Samples queued for each speaker, where more avoids crackling but adds delay (200 when zero)
+	AudioLatency       time.Duration // Latency requested from the audio device (the device's low latency when zero)
+	Volume             *float64      // Master volume from 0 to 1 (0.6 when nil)
+	HighPassFilter     string        // Output capacitor to emulate: dmg, cgb or off (matches the hardware when empty)
 	DisableVideoOutput bool
 	DisableAudioOutput bool
 	DebugCPU           bool
@@ -37,10 +74,41 @@ type Gameboy struct {
 	cpu        *cpu.CPU
 	display    *display.Display
 	interrupts *interrupts.Interrupts
+	oam        *oam.OAM
 	ppu        *ppu.PPU
 	mapper     *memory.Mapper
+	serial     *serial.Serial
+	sgb        *sgb.SGB
+	link       *link.Cable
+	printer    *printer.Printer
 	speakers   *speakers.Speakers
 	timer      *timer.Timer
+	romHash    uint32
+	frames     uint64
+	cycle      int // The next machine cycle to run in the current frame
+	savedRAM   []byte
+
+	// Palettes
+	palettes []ppu.Palette
+	palette  int
+
+	// Rewind
+	rewind         *rewindBuffer
+	rewindSnapshot bytes.Buffer
+	rewinding      bool
+
+	// Movies
+	recorder *movieRecorder
+	player   *moviePlayer
+
+	// Video recording
+	video         video.Recorder
+	videoFilename string
+
+	// Audio recording
+	wav         *wav.Writer
+	wavFilename string
+	stems       [4]*wav.Writer
 }
 
 // NewGameboy returns a new Gameboy
@@ -55,15 +123,55 @@ func New(config Config) *Gameboy {
 	// Create speakers
 	var a *audio.Audio
 	var s *speakers.Speakers
+	sampleRate := config.SampleRate
+	if sampleRate == 0 {
+		sampleRate = audio.DefaultSampleRate
+	}
+	if sampleRate < 8000 || sampleRate > 192000 {
+		panic(fmt.Sprintf("Failed to create audio (the sample rate %d is outside the range 8000 to 192000)", sampleRate))
+	}
+	if config.AudioBufferSize < 0 {
+		panic(fmt.Sprintf("Failed to create audio (the buffer size %d is negative)", config.AudioBufferSize))
+	}
+	if config.AudioLatency < 0 {
+		panic(fmt.Sprintf("Failed to create audio (the latency %v is negative)", config.AudioLatency))
+	}
 	if !config.DisableAudioOutput {
-		s = speakers.New()
-		a = audio.New(s.Left(), s.Right())
+		s = speakers.New(sampleRate, config.AudioBufferSize, config.AudioLatency)
+		a = audio.New(s.Left(), s.Right(), sampleRate)
 	} else {
-		a = audio.New(nil, nil)
+		a = audio.New(nil, nil, sampleRate)
+	}
+
+	// Load the ROM file
+	rom := readRomFile(config.RomFilename)
+
+	// Load the boot ROM which determines the hardware when it's not the CGB boot ROM
+	var bootROM []byte
+	if config.BootROM || config.BootROMFilename != "" {
+		bootROM = memory.DMGBootROM()
+		if config.BootROMFilename != "" {
+			bootROM = readBootROMFile(config.BootROMFilename)
+		}
+	}
+
+	// Header byte 0x0143 is 0x80 when the cartridge supports CGB features and also runs on a DMG, and
+	// 0xC0 when it only runs on a CGB
+	forceDMG := config.ForceDMG
+	if forceDMG && rom[0x143] == 0xc0 {
+		fmt.Println("Ignoring the request to run in DMG mode because the cartridge only runs on a CGB")
+		forceDMG = false
+	}
+	cgb := rom[0x143]&0x80 != 0 && !forceDMG && !config.SGB && (bootROM == nil || len(bootROM) == 0x900)
+
+	// Set the volume and the output capacitor
+	err := configureAudio(a, config, cgb)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create audio (%v)", err))
 	}
 
 	// Create the PPU
-	ppu := ppu.New(i, oam, config.DebugLCD)
+	ppu := ppu.New(i, oam, cgb, config.DebugLCD)
 
 	// Create the serial bus subsystem
 	serial := serial.New(config.SerialWriter)
@@ -71,41 +179,188 @@ func New(config Config) *Gameboy {
 	// Create the timer subsystem
 	timer := timer.New()
 
-	// Load the ROM file
-	rom := readRomFile(config.RomFilename)
-
 	// Create controller
 	controller := controller.New()
 
-	mapper := memory.New(rom, i, oam, ppu, controller, serial, timer, a)
+	mapper := memory.New(rom, cgb, i, oam, ppu, controller, serial, timer, a)
+	if config.AllowVRAMAccess {
+		mapper.AllowVRAMAccess()
+	}
 
 	// Create CPU
-	c := cpu.New(i, oam, config.DebugCPU, mapper)
+	c := cpu.New(i, oam, cgb, config.DebugCPU, mapper)
 
 	// Initialize internal data structures
 	c.Initialize()
 
-	// Create a display
-	var d *display.Display
-	if !config.DisableVideoOutput {
-		d = display.New(controller, c.OnInput, config.DebugLCD)
+	// Start from power-on with the boot ROM mapped over the cartridge
+	if bootROM != nil {
+		mapper.MapBootROM(bootROM)
+		c.PowerOn()
+		i.PowerOn()
+		ppu.PowerOn()
+		timer.PowerOn()
+		a.PowerOn()
 	}
 
-	return &Gameboy{
+	gb := &Gameboy{
 		audio:      a,
 		config:     config,
 		controller: controller,
 		cpu:        c,
-		display:    d,
 		interrupts: i,
+		oam:        oam,
 		ppu:        ppu,
 		mapper:     mapper,
+		serial:     serial,
 		speakers:   s,
 		timer:      timer,
+		romHash:    crc32.ChecksumIEEE(rom),
+	}
+
+	// The SGB receives commands from the game through the joypad register
+	if config.SGB {
+		gb.sgb = sgb.New(ppu)
+		controller.EnableSGB(gb.sgb.Command)
+	}
+
+	// Choose the colours for DMG games
+	gb.loadPalettes()
+
+	// Plug in a link cable or a printer
+	if config.PrinterDirectory != "" && (config.LinkListen != "" || config.LinkConnect != "") {
+		panic("A printer and a link cable can't both be plugged into the serial port")
+	}
+	if config.PrinterDirectory != "" {
+		gb.printer = printer.New(config.PrinterDirectory)
+		serial.Connect(gb.printer)
+	} else if config.LinkListen != "" {
+		gb.link, err = link.Listen(config.LinkListen, serial)
+	} else if config.LinkConnect != "" {
+		gb.link, err = link.Connect(config.LinkConnect, serial)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("Failed to connect the link cable (%v)", err))
+	}
+
+	// Create a display
+	if !config.DisableVideoOutput {
+		bounds := gb.screen().Bounds()
+		gb.display = display.New(gb.onButton, gb.onAction, bounds.Dx(), bounds.Dy())
+	}
+
+	// Restore battery-backed cart RAM from the last session
+	gb.loadSaveRAM()
+
+	// Start recording or playing back a movie
+	gb.startMovie()
+
+	// Start recording video
+	if config.RecordVideo != "" {
+		err = gb.startVideo(config.RecordVideo)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to record the video at \"%s\" (%v)", config.RecordVideo, err))
+		}
+	}
+
+	// Start recording audio
+	if config.RecordAudio != "" {
+		err = gb.startAudio(config.RecordAudio)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to record the audio at \"%s\" (%v)", config.RecordAudio, err))
+		}
+	}
+
+	// Create the rewind buffer
+	if config.RewindBudget > 0 {
+		gb.rewind = newRewindBuffer(config.RewindBudget)
+	}
+
+	return gb
+}
+
+// onButton handles Gameboy button presses from the display
+func (gb *Gameboy) onButton(button controller.Button, pressed bool) {
+	gb.onPlayerButton(0, button, pressed)
+}
+
+// onPlayerButton handles button presses on any joypad, recording them in the movie
+func (gb *Gameboy) onPlayerButton(player int, button controller.Button, pressed bool) {
+	if gb.player != nil {
+		// Live input is ignored while a movie is playing
+		return
+	}
+	if gb.recorder != nil {
+		gb.recorder.record(movieEvent{Frame: uint32(gb.frames), Cycle: uint16(gb.cycle), Player: uint8(player), Button: uint8(button), Pressed: pressed})
+	}
+	gb.pressButton(player, button, pressed)
+}
+
+// ButtonAction presses or releases a button as though it were pressed on the display
+func (gb *Gameboy) ButtonAction(button controller.Button, pressed bool) {
+	gb.onButton(button, pressed)
+}
+
+// PlayerButtonAction presses a button on one of the extra joypads an SGB game can ask for. Player 0
+// is the same joypad as ButtonAction. Players the game hasn't enabled are ignored.
+func (gb *Gameboy) PlayerButtonAction(player int, button controller.Button, pressed bool) {
+	if player < 0 || player > 3 {
+		return
+	}
+	gb.onPlayerButton(player, button, pressed)
+}
+
+func (gb *Gameboy) pressButton(player int, button controller.Button, pressed bool) {
+	gb.controller.PlayerButtonAction(player, button, pressed)
+	gb.cpu.OnInput()
+}
+
+// onAction handles emulator controls from the display
+func (gb *Gameboy) onAction(action controller.Action, slot int) {
+	switch action {
+	case controller.TakeScreenshot:
+		filename, err := gb.takeScreenshot()
+		if err != nil {
+			fmt.Printf("Failed to take screenshot (%v)\n", err)
+			return
+		}
+		fmt.Printf("Screenshot saved to %s\n", filename)
+	case controller.SaveState:
+		gb.saveStateSlot(slot)
+	case controller.LoadState:
+		if gb.movieActive() {
+			fmt.Println("Cannot load state while a movie is active")
+			return
+		}
+		gb.loadStateSlot(slot)
+	case controller.StartRewind:
+		gb.rewinding = gb.rewind != nil && !gb.movieActive()
+	case controller.StopRewind:
+		gb.rewinding = false
+	case controller.NextPalette:
+		gb.nextPalette()
+	case controller.ToggleVideo:
+		gb.toggleVideo()
+	case controller.ToggleAudio:
+		gb.toggleAudio()
+	case controller.ToggleMute:
+		gb.MuteChannel(slot, !gb.audio.Muted(slot))
+	case controller.ToggleSolo:
+		gb.SoloChannel(slot, !gb.audio.Soloed(slot))
 	}
 }
 
 func (gb *Gameboy) Cleanup() {
+	gb.stopMovie()
+	gb.stopVideo()
+	gb.stopAudio()
+	gb.flushSaveRAM()
+	if gb.printer != nil {
+		gb.printer.Flush()
+	}
+	if gb.link != nil {
+		gb.link.Close()
+	}
 	if gb.speakers != nil {
 		gb.speakers.Cleanup()
 	}
@@ -126,26 +381,38 @@ func readRomFile(romFilename string) []byte {
 	return rom
 }
 
+func readBootROMFile(bootROMFilename string) []byte {
+	bootROM, err := ioutil.ReadFile(bootROMFilename)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to read the boot ROM file at \"%s\" (%v)", bootROMFilename, err))
+	}
+	if len(bootROM) != 0x100 && len(bootROM) != 0x900 {
+		panic(fmt.Sprintf("Boot ROM file at \"%s\" is %d bytes but should be 256 bytes (DMG) or 2304 bytes (CGB)", bootROMFilename, len(bootROM)))
+	}
+	return bootROM
+}
+
 func (gb *Gameboy) runFrame(ctx context.Context) bool {
-	// The Game Boy clock runs at 4.194304MHz
-	// One machine cycle is 4 clock cycles
-	// Each loop iteration below represents one machine cycle
-	// Each LCD frame is 17556 machine cycles
-	for mtick := 0; mtick < 17556; mtick++ {
-		gb.cpu.ExecuteMachineCycle()
-		gb.ppu.EndMachineCycle()
-		gb.mapper.EndMachineCycle()
-		gb.audio.EndMachineCycle()
-		timerInterruptRequested := gb.timer.EndMachineCycle()
-		if timerInterruptRequested {
-			gb.interrupts.RequestTimer()
-		}
+	if gb.startFrame() {
+		return true
 	}
-	frame := gb.ppu.Frame()
-	if gb.display != nil {
-		return gb.display.RenderFrame(frame)
+	if gb.rewinding {
+		if gb.link != nil {
+			// Keep responding to the peer so it isn't stalled while we rewind
+			gb.link.Poll()
+		}
+		gb.rewindFrame()
+	} else {
+		// The Game Boy clock runs at 4.194304MHz
+		// One machine cycle is 4 clock cycles
+		// Each loop iteration below represents one machine cycle
+		// Each LCD frame is 17556 machine cycles
+		for mtick := 0; mtick < 17556; mtick++ {
+			gb.runMachineCycle(mtick)
+		}
+		gb.endFrame()
 	}
-	return false
+	return gb.finishFrame()
 
 	// The emulator can run a frame much faster than a real Gameboy when running on a modern computer.
 	// There is no need to sleep now between frames however, because the audio subsystem consumes
@@ -158,6 +425,101 @@ func (gb *Gameboy) runFrame(ctx context.Context) bool {
 
 }
 
+// runMachineCycle runs every subsystem for one machine cycle of the frame
+func (gb *Gameboy) runMachineCycle(mtick int) {
+	if gb.link != nil && mtick%114 == 0 {
+		// Respond to the peer once per LCD line so its transfers complete promptly
+		gb.link.Poll()
+	}
+	if gb.player != nil {
+		gb.player.apply(gb, gb.frames, mtick)
+	}
+	gb.executeCPUCycle()
+	gb.ppu.EndMachineCycle()
+	gb.mapper.EndMachineCycle()
+	gb.audio.EndMachineCycle()
+	gb.endTimerCycle()
+	if gb.mapper.DoubleSpeed() {
+		// In CGB double speed mode the CPU, DMA, timer and serial run twice as fast as the PPU and APU
+		gb.executeCPUCycle()
+		gb.mapper.EndMachineCycle()
+		gb.endTimerCycle()
+	}
+	gb.cycle = mtick + 1
+}
+
+// executeCPUCycle runs the CPU for a machine cycle unless HDMA has stalled it
+func (gb *Gameboy) executeCPUCycle() {
+	if gb.mapper.StallCPU() {
+		return
+	}
+	gb.cpu.ExecuteMachineCycle()
+}
+
+// endTimerCycle advances the timer and the serial port which is clocked from it
+func (gb *Gameboy) endTimerCycle() {
+	timerInterruptRequested := gb.timer.EndMachineCycle()
+	if timerInterruptRequested {
+		gb.interrupts.RequestTimer()
+	}
+	serialInterruptRequested := gb.serial.EndMachineCycle(gb.timer.Counter())
+	if serialInterruptRequested {
+		gb.interrupts.RequestSerial()
+	}
+}
+
+// startFrame does the housekeeping before a frame runs and returns true if the emulator should stop
+// because the frame limit has been reached or headless movie playback is complete
+func (gb *Gameboy) startFrame() bool {
+	if gb.config.FrameLimit > 0 && gb.frames >= uint64(gb.config.FrameLimit) {
+		return true
+	}
+	if gb.player != nil && gb.player.finished(gb.frames) {
+		gb.player = nil
+		if gb.display == nil {
+			// Headless playback is complete once the movie runs out
+			return true
+		}
+		fmt.Println("Movie playback finished")
+	}
+	return false
+}
+
+// endFrame does the housekeeping after all the machine cycles in a frame have run
+func (gb *Gameboy) endFrame() {
+	gb.frames++
+	gb.cycle = 0
+	if gb.sgb != nil {
+		gb.sgb.EndFrame()
+	}
+	if gb.rewind != nil {
+		gb.recordRewind()
+	}
+}
+
+// finishFrame records the frame to any video being made and shows it on the display, returning true
+// if the display was closed
+func (gb *Gameboy) finishFrame() bool {
+	gb.recordVideoFrame()
+	return gb.renderFrame()
+}
+
+// renderFrame shows the frame on the display and returns true if the display was closed
+func (gb *Gameboy) renderFrame() bool {
+	if gb.display != nil {
+		return gb.display.RenderFrame(gb.screen())
+	}
+	return false
+}
+
+// screen returns the image shown on the display which the SGB colourises and surrounds with a border
+func (gb *Gameboy) screen() *image.RGBA {
+	if gb.sgb != nil {
+		return gb.sgb.Frame()
+	}
+	return gb.ppu.Frame()
+}
+
 // Run the Gameboy
 func (gb *Gameboy) Run(ctx context.Context) {
 	defer gb.Cleanup()
@@ -173,6 +535,11 @@ func (gb *Gameboy) Run(ctx context.Context) {
 			}
 		}
 
+		// Periodically persist battery-backed cart RAM in case we don't exit cleanly
+		if gb.frames%saveInterval == 0 {
+			gb.flushSaveRAM()
+		}
+
 		// Show FPS
 		// if frames == 0 {
 		// t1 := time.Now().UnixMicro()
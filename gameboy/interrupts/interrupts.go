@@ -23,12 +23,17 @@ type Interrupts struct {
 // New Interrupts
 func New() *Interrupts {
 	i := &Interrupts{}
-	i.Enable()
 	i.WriteIE(0x00)
 	i.WriteIF(0x01)
 	return i
 }
 
+// PowerOn resets interrupts to their state before the boot ROM runs
+func (i *Interrupts) PowerOn() {
+	i.Disable()
+	i.WriteIF(0x00)
+}
+
 // IME - Interrupt Master Enable Flag (Write Only)
 //   0 - Disable all Interrupts
 //   1 - Enable all Interrupts that are enabled in IE Register (FFFF)
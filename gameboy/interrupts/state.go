@@ -0,0 +1,35 @@
+package interrupts
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// state is the serialisable form of the interrupts
+type state struct {
+	IME bool
+	IE  uint8
+	IF  uint8
+}
+
+// SaveState writes the interrupt state
+func (i *Interrupts) SaveState(w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, &state{
+		IME: i.ime,
+		IE:  i.ReadIE(),
+		IF:  i.ReadIF(),
+	})
+}
+
+// LoadState restores the interrupt state
+func (i *Interrupts) LoadState(r io.Reader) error {
+	var s state
+	err := binary.Read(r, binary.LittleEndian, &s)
+	if err != nil {
+		return err
+	}
+	i.ime = s.IME
+	i.WriteIE(s.IE)
+	i.WriteIF(s.IF)
+	return nil
+}
@@ -0,0 +1,129 @@
+package link
+
+import (
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/scottyw/tetromino/gameboy/serial"
+)
+
+// Messages are two bytes: the message type followed by the byte being exchanged
+const (
+	transfer = uint8(iota) // A byte clocked out by the sender's internal clock
+	response               // The byte shifted back to the sender in return
+)
+
+// Cable implements a link cable to another Gameboy over TCP
+type Cable struct {
+	conn      net.Conn
+	serial    *serial.Serial
+	transfers chan uint8
+	responses chan uint8
+	done      chan struct{}
+}
+
+// Listen waits for another Gameboy to connect and plugs the cable into the serial port
+func Listen(address string, s *serial.Serial) (*Cable, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+	fmt.Printf("Waiting for link cable connection on %s\n", listener.Addr())
+	return accept(listener, s)
+}
+
+func accept(listener net.Listener, s *serial.Serial) (*Cable, error) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return newCable(conn, s), nil
+}
+
+// Connect to another Gameboy that is listening and plugs the cable into the serial port
+func Connect(address string, s *serial.Serial) (*Cable, error) {
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	return newCable(conn, s), nil
+}
+
+func newCable(conn net.Conn, s *serial.Serial) *Cable {
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.SetNoDelay(true)
+	}
+	c := &Cable{
+		conn:      conn,
+		serial:    s,
+		transfers: make(chan uint8, 16),
+		responses: make(chan uint8, 16),
+		done:      make(chan struct{}),
+	}
+	go c.receive()
+	s.ConnectRemote(c)
+	return c
+}
+
+// receive reads messages from the peer until the connection is closed
+func (c *Cable) receive() {
+	defer close(c.done)
+	message := make([]byte, 2)
+	for {
+		_, err := io.ReadFull(c.conn, message)
+		if err != nil {
+			return
+		}
+		switch message[0] {
+		case transfer:
+			c.transfers <- message[1]
+		case response:
+			c.responses <- message[1]
+		}
+	}
+}
+
+func (c *Cable) send(messageType, value uint8) {
+	_, err := c.conn.Write([]byte{messageType, value})
+	if err != nil {
+		fmt.Printf("Link cable disconnected (%v)\n", err)
+		c.conn.Close()
+	}
+}
+
+// Send passes a byte clocked by this Gameboy to the peer. The byte shifted back is passed to the
+// serial port by Poll when it arrives, so the emulator carries on running in the meantime. If the
+// peer is stopped or paused then the serial port times out as though nothing were connected.
+func (c *Cable) Send(out uint8) {
+	c.send(transfer, out)
+}
+
+// Poll responds to any transfers clocked by the peer and completes any transfer waiting for the
+// peer's response. It must be called regularly from the same goroutine that runs the Gameboy so
+// that neither side is kept waiting.
+func (c *Cable) Poll() {
+	for {
+		select {
+		case in := <-c.transfers:
+			// This includes both Gameboys clocking a transfer at once, when the line reads high
+			c.send(response, c.serial.Exchange(in))
+		case in := <-c.responses:
+			c.serial.Reply(in)
+		default:
+			select {
+			case <-c.done:
+				// Nothing will respond once the peer has disconnected
+				c.serial.Reply(0xff)
+			default:
+			}
+			return
+		}
+	}
+}
+
+// Close disconnects the cable
+func (c *Cable) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,168 @@
+package link
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/scottyw/tetromino/gameboy/serial"
+)
+
+func connectLoopback(t *testing.T, master, slave *serial.Serial) (*Cable, *Cable) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	accepted := make(chan *Cable)
+	go func() {
+		cable, err := accept(listener, slave)
+		if err != nil {
+			t.Error(err)
+		}
+		accepted <- cable
+	}()
+	masterCable, err := Connect(listener.Addr().String(), master)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return masterCable, <-accepted
+}
+
+// runUntilInterrupt runs the serial port until a transfer completes. It runs at about the speed of a
+// real Gameboy, one LCD line at a time, so that the peer can respond before the transfer times out.
+func runUntilInterrupt(s *serial.Serial, cable *Cable) bool {
+	deadline := time.Now().Add(5 * time.Second)
+	var counter uint16
+	for time.Now().Before(deadline) {
+		time.Sleep(27 * time.Microsecond)
+		cable.Poll()
+		for i := 0; i < 114; i++ {
+			counter += 4
+			if s.EndMachineCycle(counter) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestTransferOverLoopback(t *testing.T) {
+	master := serial.New(nil)
+	slave := serial.New(nil)
+	masterCable, slaveCable := connectLoopback(t, master, slave)
+	defer masterCable.Close()
+	defer slaveCable.Close()
+
+	slave.WriteSB(0x22)
+	slave.WriteSC(0x80)
+	slaveDone := make(chan bool)
+	go func() {
+		slaveDone <- runUntilInterrupt(slave, slaveCable)
+	}()
+
+	master.WriteSB(0x11)
+	master.WriteSC(0x81)
+	if !runUntilInterrupt(master, masterCable) {
+		t.Fatal("Master transfer did not complete")
+	}
+	if !<-slaveDone {
+		t.Fatal("Slave transfer did not complete")
+	}
+	if master.ReadSB() != 0x22 {
+		t.Errorf("Wrong master SB: 0x%02x", master.ReadSB())
+	}
+	if slave.ReadSB() != 0x11 {
+		t.Errorf("Wrong slave SB: 0x%02x", slave.ReadSB())
+	}
+	if master.ReadSC() != 0x7f {
+		t.Errorf("Wrong master SC: 0x%02x", master.ReadSC())
+	}
+	if slave.ReadSC() != 0x7e {
+		t.Errorf("Wrong slave SC: 0x%02x", slave.ReadSC())
+	}
+}
+
+func TestTransferAfterDisconnect(t *testing.T) {
+	master := serial.New(nil)
+	slave := serial.New(nil)
+	masterCable, slaveCable := connectLoopback(t, master, slave)
+	defer masterCable.Close()
+	slaveCable.Close()
+
+	// The transfer completes as though nothing were connected
+	master.WriteSB(0x11)
+	master.WriteSC(0x81)
+	if !runUntilInterrupt(master, masterCable) {
+		t.Fatal("Master transfer did not complete")
+	}
+	if master.ReadSB() != 0xff {
+		t.Errorf("Wrong master SB: 0x%02x", master.ReadSB())
+	}
+}
+
+func TestTransferWhilePeerPaused(t *testing.T) {
+	master := serial.New(nil)
+	slave := serial.New(nil)
+	masterCable, slaveCable := connectLoopback(t, master, slave)
+	defer masterCable.Close()
+	defer slaveCable.Close()
+	slave.WriteSB(0x22)
+	slave.WriteSC(0x80)
+
+	// The master keeps running while it waits for the slave to respond
+	master.WriteSB(0x11)
+	master.WriteSC(0x81)
+	var counter uint16
+	for i := 0; i < 100000; i++ {
+		if i%114 == 0 {
+			masterCable.Poll()
+		}
+		counter += 4
+		if master.EndMachineCycle(counter) {
+			t.Fatal("Master transfer completed before the slave responded")
+		}
+	}
+
+	// The transfer completes once the slave catches up
+	if !runUntilInterrupt(slave, slaveCable) {
+		t.Fatal("Slave transfer did not complete")
+	}
+	if !runUntilInterrupt(master, masterCable) {
+		t.Fatal("Master transfer did not complete")
+	}
+	if master.ReadSB() != 0x22 {
+		t.Errorf("Wrong master SB: 0x%02x", master.ReadSB())
+	}
+}
+
+func TestTransferTimeout(t *testing.T) {
+	master := serial.New(nil)
+	slave := serial.New(nil)
+	masterCable, slaveCable := connectLoopback(t, master, slave)
+	defer masterCable.Close()
+	defer slaveCable.Close()
+
+	// The slave never responds so after about a second of machine cycles the transfer carries on as
+	// though nothing were connected
+	master.WriteSB(0x11)
+	master.WriteSC(0x81)
+	var counter uint16
+	var cycles int
+	for !master.EndMachineCycle(counter) {
+		if cycles%114 == 0 {
+			masterCable.Poll()
+		}
+		counter += 4
+		cycles++
+		if cycles > 2000000 {
+			t.Fatal("Master transfer did not complete")
+		}
+	}
+	if cycles < 1000000 {
+		t.Errorf("Master transfer timed out after only %d machine cycles", cycles)
+	}
+	if master.ReadSB() != 0xff {
+		t.Errorf("Wrong master SB: 0x%02x", master.ReadSB())
+	}
+}
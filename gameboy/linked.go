@@ -0,0 +1,75 @@
+package gameboy
+
+import (
+	"context"
+)
+
+// Linked is a pair of Gameboys connected by a link cable and run in lockstep
+type Linked struct {
+	a *Gameboy
+	b *Gameboy
+}
+
+// Link connects the serial ports of two Gameboys in the same process with a link cable.
+// They must then be run together with the Linked runner rather than independently.
+func Link(a, b *Gameboy) *Linked {
+	a.serial.Connect(b.serial)
+	b.serial.Connect(a.serial)
+	return &Linked{a: a, b: b}
+}
+
+// RunFrame advances both Gameboys by one frame, interleaving them a machine cycle at a
+// time so that serial transfers between them complete exactly as they would on hardware.
+// Rewinding either Gameboy steps both back a frame so they stay in lockstep, which needs
+// both to keep rewind history. It returns true if either display was closed or either
+// Gameboy should stop.
+func (l *Linked) RunFrame() bool {
+	stopA := l.a.startFrame()
+	stopB := l.b.startFrame()
+	if stopA || stopB {
+		return true
+	}
+	if (l.a.rewinding || l.b.rewinding) && l.a.rewind != nil && l.b.rewind != nil {
+		l.a.rewindFrame()
+		l.b.rewindFrame()
+	} else {
+		for mtick := 0; mtick < 17556; mtick++ {
+			l.a.runMachineCycle(mtick)
+			l.b.runMachineCycle(mtick)
+		}
+		l.a.endFrame()
+		l.b.endFrame()
+	}
+	closedA := l.a.finishFrame()
+	closedB := l.b.finishFrame()
+	return closedA || closedB
+}
+
+// RunFrames advances both Gameboys by the given number of frames
+func (l *Linked) RunFrames(frames int) {
+	for i := 0; i < frames; i++ {
+		l.RunFrame()
+	}
+}
+
+// Run both Gameboys until the context is done or a display is closed
+func (l *Linked) Run(ctx context.Context) {
+	defer l.a.Cleanup()
+	defer l.b.Cleanup()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			if l.RunFrame() {
+				return
+			}
+		}
+
+		// Periodically persist battery-backed cart RAM in case we don't exit cleanly
+		if l.a.frames%saveInterval == 0 {
+			l.a.flushSaveRAM()
+			l.b.flushSaveRAM()
+		}
+	}
+}
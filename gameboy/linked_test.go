@@ -0,0 +1,124 @@
+package gameboy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/scottyw/tetromino/gameboy/controller"
+	"github.com/scottyw/tetromino/gameboy/memory"
+)
+
+func TestLinkedTransfer(t *testing.T) {
+	config := Config{
+		RomFilename:        "testdata/mts-20221022-1430-8d742b9/acceptance/div_timing.gb",
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	}
+	a := New(config)
+	b := New(config)
+	linked := Link(a, b)
+
+	// Let the test ROMs finish before using the serial ports
+	linked.RunFrames(60)
+
+	// B waits for a transfer clocked by A
+	b.mapper.Write(memory.SB, 0x22)
+	b.mapper.Write(memory.SC, 0x80)
+	a.mapper.Write(memory.SB, 0x11)
+	a.mapper.Write(memory.SC, 0x81)
+	linked.RunFrame()
+
+	if a.mapper.Read(memory.SB) != 0x22 {
+		t.Errorf("Wrong SB on A: 0x%02x", a.mapper.Read(memory.SB))
+	}
+	if b.mapper.Read(memory.SB) != 0x11 {
+		t.Errorf("Wrong SB on B: 0x%02x", b.mapper.Read(memory.SB))
+	}
+	for _, gb := range []*Gameboy{a, b} {
+		if gb.mapper.Read(memory.SC)&0x80 != 0 {
+			t.Error("Transfer still in progress")
+		}
+		if gb.mapper.Read(memory.IF)&0x08 == 0 {
+			t.Error("Serial interrupt not requested")
+		}
+	}
+}
+
+func TestLinkedBlargg(t *testing.T) {
+	var outputs [2]*bytes.Buffer
+	var gameboys [2]*Gameboy
+	for i := range gameboys {
+		outputs[i] = &bytes.Buffer{}
+		gameboys[i] = New(Config{
+			RomFilename:        "testdata/blargg/instr_timing/instr_timing.gb",
+			DisableVideoOutput: true,
+			DisableAudioOutput: true,
+			SerialWriter:       outputs[i],
+		})
+	}
+	linked := Link(gameboys[0], gameboys[1])
+
+	// Both CPUs must run correctly side by side in the same process
+	for frame := 0; frame < 600; frame++ {
+		linked.RunFrame()
+		if strings.Contains(outputs[0].String(), "Passed") && strings.Contains(outputs[1].String(), "Passed") {
+			return
+		}
+	}
+	t.Errorf("Linked test ROMs did not pass:\n%s\n%s", outputs[0], outputs[1])
+}
+
+func TestLinkedFrameLimit(t *testing.T) {
+	config := Config{
+		RomFilename:        "testdata/mts-20221022-1430-8d742b9/acceptance/div_timing.gb",
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+		FrameLimit:         5,
+	}
+	linked := Link(New(config), New(config))
+	for frame := 0; frame < 5; frame++ {
+		if linked.RunFrame() {
+			t.Fatalf("stopped early at frame %d", frame)
+		}
+	}
+	if !linked.RunFrame() {
+		t.Error("expected the frame limit to stop the linked Gameboys")
+	}
+}
+
+func TestLinkedRewind(t *testing.T) {
+	config := Config{
+		RomFilename:        "testdata/blargg/instr_timing/instr_timing.gb",
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+		RewindBudget:       16 * 1024 * 1024,
+	}
+	a := New(config)
+	b := New(config)
+	linked := Link(a, b)
+	linked.RunFrames(30)
+	var expected [2]bytes.Buffer
+	for i, gb := range []*Gameboy{a, b} {
+		err := gb.SaveState(&expected[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	linked.RunFrames(10)
+
+	// Rewinding one Gameboy takes both back to where they were
+	a.onAction(controller.StartRewind, 0)
+	linked.RunFrames(10)
+	a.onAction(controller.StopRewind, 0)
+	for i, gb := range []*Gameboy{a, b} {
+		actual := &bytes.Buffer{}
+		err := gb.SaveState(actual)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(expected[i].Bytes(), actual.Bytes()) {
+			t.Errorf("machine state of Gameboy %d differs after rewinding", i)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package memory
 
+// DMGBootROM returns the boot ROM of the original DMG which scrolls the Nintendo logo and checks the cartridge header
+func DMGBootROM() []byte {
+	return bios[:]
+}
+
 var bios = [...]uint8{
 	0x31, 0xFE, 0xFF, 0xAF, 0x21, 0xFF, 0x9F, 0x32,
 	0xCB, 0x7C, 0x20, 0xFB, 0x21, 0x26, 0xFF, 0x0E,
@@ -18,58 +18,86 @@ const (
 	// Register constants
 	//
 
-	JOYP = 0xFF00
-	SB   = 0xFF01
-	SC   = 0xFF02
-	DIV  = 0xFF04
-	TIMA = 0xFF05
-	TMA  = 0xFF06
-	TAC  = 0xFF07
-	IF   = 0xFF0F
-	NR10 = 0xFF10
-	NR11 = 0xFF11
-	NR12 = 0xFF12
-	NR13 = 0xFF13
-	NR14 = 0xFF14
-	NR21 = 0xFF16
-	NR22 = 0xFF17
-	NR23 = 0xFF18
-	NR24 = 0xFF19
-	NR30 = 0xFF1A
-	NR31 = 0xFF1B
-	NR32 = 0xFF1C
-	NR33 = 0xFF1D
-	NR34 = 0xFF1E
-	NR41 = 0xFF20
-	NR42 = 0xFF21
-	NR43 = 0xFF22
-	NR44 = 0xFF23
-	NR50 = 0xFF24
-	NR51 = 0xFF25
-	NR52 = 0xFF26
-	LCDC = 0xFF40
-	STAT = 0xFF41
-	SCY  = 0xFF42
-	SCX  = 0xFF43
-	LY   = 0xFF44
-	LYC  = 0xFF45
-	DMA  = 0xFF46
-	BGP  = 0xFF47
-	OBP0 = 0xFF48
-	OBP1 = 0xFF49
-	WY   = 0xFF4A
-	WX   = 0xFF4B
-	IE   = 0xFFFF
+	JOYP  = 0xFF00
+	SB    = 0xFF01
+	SC    = 0xFF02
+	DIV   = 0xFF04
+	TIMA  = 0xFF05
+	TMA   = 0xFF06
+	TAC   = 0xFF07
+	IF    = 0xFF0F
+	NR10  = 0xFF10
+	NR11  = 0xFF11
+	NR12  = 0xFF12
+	NR13  = 0xFF13
+	NR14  = 0xFF14
+	NR21  = 0xFF16
+	NR22  = 0xFF17
+	NR23  = 0xFF18
+	NR24  = 0xFF19
+	NR30  = 0xFF1A
+	NR31  = 0xFF1B
+	NR32  = 0xFF1C
+	NR33  = 0xFF1D
+	NR34  = 0xFF1E
+	NR41  = 0xFF20
+	NR42  = 0xFF21
+	NR43  = 0xFF22
+	NR44  = 0xFF23
+	NR50  = 0xFF24
+	NR51  = 0xFF25
+	NR52  = 0xFF26
+	LCDC  = 0xFF40
+	STAT  = 0xFF41
+	SCY   = 0xFF42
+	SCX   = 0xFF43
+	LY    = 0xFF44
+	LYC   = 0xFF45
+	DMA   = 0xFF46
+	BGP   = 0xFF47
+	OBP0  = 0xFF48
+	OBP1  = 0xFF49
+	WY    = 0xFF4A
+	WX    = 0xFF4B
+	KEY1  = 0xFF4D
+	VBK   = 0xFF4F
+	BOOT  = 0xFF50
+	HDMA1 = 0xFF51
+	HDMA2 = 0xFF52
+	HDMA3 = 0xFF53
+	HDMA4 = 0xFF54
+	HDMA5 = 0xFF55
+	BCPS  = 0xFF68
+	BCPD  = 0xFF69
+	OCPS  = 0xFF6A
+	OCPD  = 0xFF6B
+	SVBK  = 0xFF70
+	IE    = 0xFFFF
 )
 
 // Memory allows read and write access to memory
 type Mapper struct {
-	internalRAM [0x2000]byte
+	internalRAM [0x8000]byte
 	zeroPage    [0x8f]byte
+	bootROM     []byte
+	bootMapped  bool
+	cgb         bool
+	svbk        uint8
+	key1        uint8
+	doubleSpeed bool
+	rtcPhase    bool
+	hdmaSource  uint16
+	hdmaDest    uint16
+	hdmaLength  uint8
+	hdmaActive  bool
+	hdmaStall   int
+	ppuMode     uint8
+	allowVRAM   bool
 	audio       *audio.Audio
 	controller  *controller.Controller
 	interrupts  *interrupts.Interrupts
 	mbc         mbc
+	battery     bool
 	rtc         *rtc
 	oam         *oam.OAM
 	ppu         *ppu.PPU
@@ -78,11 +106,18 @@ type Mapper struct {
 }
 
 // NewMemory creates the memory struct and initializes it with ROM contents and default values
-func New(rom []byte, interrupts *interrupts.Interrupts, oam *oam.OAM, ppu *ppu.PPU, controller *controller.Controller, serial *serial.Serial, timer *timer.Timer, audio *audio.Audio) *Mapper {
+func New(rom []byte, cgb bool, interrupts *interrupts.Interrupts, oam *oam.OAM, ppu *ppu.PPU, controller *controller.Controller, serial *serial.Serial, timer *timer.Timer, audio *audio.Audio) *Mapper {
 	rtc := newRTC()
 	mbc := newMBC(rom, rtc)
+	var battery bool
+	if len(rom) > 0x0147 {
+		battery = hasBattery(rom[0x0147])
+	}
 	return &Mapper{
+		cgb:        cgb,
+		hdmaLength: 0x7f,
 		mbc:        mbc,
+		battery:    battery,
 		rtc:        rtc,
 		oam:        oam,
 		interrupts: interrupts,
@@ -94,26 +129,66 @@ func New(rom []byte, interrupts *interrupts.Interrupts, oam *oam.OAM, ppu *ppu.P
 	}
 }
 
+// EndMachineCycle runs DMA after each CPU machine cycle, of which there are twice as many in double speed mode
 func (m *Mapper) EndMachineCycle() {
-	m.oam.TickDMA(m.Read)
-	m.rtc.tick()
+	m.oam.TickDMA(m.readForDMA)
+
+	// The real-time clock isn't affected by double speed mode
+	m.rtcPhase = !m.rtcPhase
+	if !m.doubleSpeed || m.rtcPhase {
+		m.rtc.tick()
+	}
+
+	// H-blank DMA copies a block at the start of each h-blank. The PPU stays in mode 0 while the LCD is
+	// off so, unlike real hardware, a transfer started with the LCD off waits until it's switched on.
+	mode := m.ppu.Mode()
+	if m.hdmaActive && mode == 0 && m.ppuMode != 0 {
+		m.hdmaActive = !m.copyHDMABlock()
+	}
+	m.ppuMode = mode
+}
+
+// wramAddr returns the offset in internal RAM of an address in the range C000-DFFF. The CGB has
+// seven switchable banks at D000-DFFF selected by SVBK where bank 0 also selects bank 1.
+func (m *Mapper) wramAddr(addr uint16) uint16 {
+	if addr < 0xd000 {
+		return addr - 0xc000
+	}
+	bank := uint16(m.svbk)
+	if bank == 0 {
+		bank = 1
+	}
+	return bank*0x1000 + addr - 0xd000
 }
 
 // Read a byte from the chosen memory location
 func (m *Mapper) Read(addr uint16) byte {
 	switch {
+	case m.bootMapped && addr < 0x0100:
+		return m.bootROM[addr]
+	case m.bootMapped && addr >= 0x0200 && int(addr) < len(m.bootROM):
+		// The CGB boot ROM leaves a gap for the cartridge header
+		return m.bootROM[addr]
 	case addr < 0x8000:
 		return m.mbc.Read(addr)
 	case addr < 0xa000:
+		if m.vramBlocked() {
+			return 0xff
+		}
 		return m.ppu.ReadVideoRAM(addr)
 	case addr < 0xc000:
 		return m.mbc.Read(addr)
 	case addr < 0xe000:
-		return m.internalRAM[addr-0xc000]
+		return m.internalRAM[m.wramAddr(addr)]
 	case addr < 0xfe00:
-		return m.internalRAM[addr-0xe000]
+		return m.internalRAM[m.wramAddr(addr-0x2000)]
 	case addr < 0xff00:
-		return m.oam.Read(addr)
+		// Blocked reads still count as OAM accesses for the OAM corruption bug
+		value := m.oam.Read(addr)
+		if m.oamBlocked() {
+			return 0xff
+		}
+		return value
 	case addr == JOYP:
 		return m.controller.ReadJOYP()
 	case addr == SB:
@@ -201,6 +276,22 @@ func (m *Mapper) Read(addr uint16) byte {
 		return m.ppu.ReadWY()
 	case addr == WX:
 		return m.ppu.ReadWX()
+	case addr == KEY1 && m.cgb:
+		return m.readKEY1()
+	case addr == VBK && m.cgb:
+		return m.ppu.ReadVBK()
+	case addr == HDMA5 && m.cgb:
+		return m.readHDMA5()
+	case addr == BCPS && m.cgb:
+		return m.ppu.ReadBCPS()
+	case addr == BCPD && m.cgb:
+		return m.ppu.ReadBCPD()
+	case addr == OCPS && m.cgb:
+		return m.ppu.ReadOCPS()
+	case addr == OCPD && m.cgb:
+		return m.ppu.ReadOCPD()
+	case addr == SVBK && m.cgb:
+		return m.svbk | 0xf8
 	case addr < 0xff80:
 		// Default if a non-hardware register is read
 		return 0xff
@@ -219,15 +310,21 @@ func (m *Mapper) Write(addr uint16, value byte) {
 	case addr < 0x8000:
 		m.mbc.Write(addr, value)
 	case addr < 0xa000:
-		m.ppu.WriteVideoRAM(addr, value)
+		if !m.vramBlocked() {
+			m.ppu.WriteVideoRAM(addr, value)
+		}
 	case addr < 0xc000:
 		m.mbc.Write(addr, value)
 	case addr < 0xe000:
-		m.internalRAM[addr-0xc000] = value
+		m.internalRAM[m.wramAddr(addr)] = value
 	case addr < 0xfe00:
-		m.internalRAM[addr-0xe000] = value
+		m.internalRAM[m.wramAddr(addr-0x2000)] = value
 	case addr < 0xff00:
-		m.oam.Write(addr, value)
+		if m.oamBlocked() {
+			m.oam.TriggerWriteCorruption(addr)
+		} else {
+			m.oam.Write(addr, value)
+		}
 	case addr == JOYP:
 		m.controller.WriteJOYP(value)
 	case addr == SB:
@@ -314,6 +411,35 @@ func (m *Mapper) Write(addr uint16, value byte) {
 		m.ppu.WriteWY(value)
 	case addr == WX:
 		m.ppu.WriteWX(value)
+	case addr == KEY1 && m.cgb:
+		m.key1 = value & 0x01
+	case addr == VBK && m.cgb:
+		m.ppu.WriteVBK(value)
+	case addr == HDMA1 && m.cgb:
+		m.hdmaSource = uint16(value)<<8 | m.hdmaSource&0x00f0
+	case addr == HDMA2 && m.cgb:
+		m.hdmaSource = m.hdmaSource&0xff00 | uint16(value&0xf0)
+	case addr == HDMA3 && m.cgb:
+		m.hdmaDest = uint16(value&0x1f)<<8 | m.hdmaDest&0x00f0
+	case addr == HDMA4 && m.cgb:
+		m.hdmaDest = m.hdmaDest&0x1f00 | uint16(value&0xf0)
+	case addr == HDMA5 && m.cgb:
+		m.writeHDMA5(value)
+	case addr == BCPS && m.cgb:
+		m.ppu.WriteBCPS(value)
+	case addr == BCPD && m.cgb:
+		m.ppu.WriteBCPD(value)
+	case addr == OCPS && m.cgb:
+		m.ppu.WriteOCPS(value)
+	case addr == OCPD && m.cgb:
+		m.ppu.WriteOCPD(value)
+	case addr == SVBK && m.cgb:
+		m.svbk = value & 0x07
+	case addr == BOOT:
+		// Any write with bit 0 set unmaps the boot ROM until the next power cycle
+		if value&0x01 != 0 {
+			m.bootMapped = false
+		}
 	case addr < 0xff80:
 		// Do nothing if a non-hardware register is written
 	case addr < 0xffff:
@@ -325,6 +451,133 @@ func (m *Mapper) Write(addr uint16, value byte) {
 	}
 }
 
+// AllowVRAMAccess lets the CPU read and write VRAM and OAM while the PPU is using them, which real
+// hardware doesn't allow. This helps when debugging games that don't wait for the right PPU mode.
+func (m *Mapper) AllowVRAMAccess() {
+	m.allowVRAM = true
+}
+
+// readForDMA reads a byte for OAM DMA or HDMA. Only the CPU is blocked from VRAM while the PPU is
+// using it so DMA reads it directly.
+func (m *Mapper) readForDMA(addr uint16) byte {
+	if addr >= 0x8000 && addr < 0xa000 {
+		return m.ppu.ReadVideoRAM(addr)
+	}
+	return m.Read(addr)
+}
+
+// vramBlocked returns true while the PPU is reading VRAM during the pixel transfer in mode 3
+func (m *Mapper) vramBlocked() bool {
+	return !m.allowVRAM && m.ppu.VRAMBlocked()
+}
+
+// oamBlocked returns true while the PPU is reading OAM during the OAM search in mode 2 and the pixel
+// transfer in mode 3
+func (m *Mapper) oamBlocked() bool {
+	return !m.allowVRAM && m.ppu.OAMBlocked()
+}
+
+// MapBootROM maps a boot ROM over the start of the cartridge until it's unmapped by writing to BOOT
+func (m *Mapper) MapBootROM(bootROM []byte) {
+	m.bootROM = bootROM
+	m.bootMapped = true
+}
+
+// BootROMMapped returns true while the boot ROM is still running
+func (m *Mapper) BootROMMapped() bool {
+	return m.bootMapped
+}
+
+// SpeedSwitchRequested returns true if KEY1 has been armed for a speed switch by the next STOP
+func (m *Mapper) SpeedSwitchRequested() bool {
+	return m.key1&0x01 != 0
+}
+
+// SwitchSpeed toggles between normal and double speed mode, which also resets DIV
+func (m *Mapper) SwitchSpeed() {
+	m.key1 = 0
+	m.doubleSpeed = !m.doubleSpeed
+	m.timer.Reset()
+}
+
+// DoubleSpeed returns true if the CPU is running in CGB double speed mode
+func (m *Mapper) DoubleSpeed() bool {
+	return m.doubleSpeed
+}
+
+func (m *Mapper) readKEY1() uint8 {
+	value := 0x7e | m.key1
+	if m.doubleSpeed {
+		value |= 0x80
+	}
+	return value
+}
+
+// StallCPU returns true if the CPU must skip a machine cycle because HDMA is copying to VRAM
+func (m *Mapper) StallCPU() bool {
+	if m.hdmaStall == 0 {
+		return false
+	}
+	m.hdmaStall--
+	return true
+}
+
+func (m *Mapper) readHDMA5() uint8 {
+	if m.hdmaActive {
+		return m.hdmaLength
+	}
+	return 0x80 | m.hdmaLength
+}
+
+func (m *Mapper) writeHDMA5(value uint8) {
+	// Clearing bit 7 while an h-blank transfer is running cancels it
+	if m.hdmaActive && value&0x80 == 0 {
+		m.hdmaActive = false
+		return
+	}
+	m.hdmaLength = value & 0x7f
+	if value&0x80 != 0 {
+		m.hdmaActive = true
+		return
+	}
+	// General purpose DMA copies everything at once and then stalls the CPU for as long as the
+	// transfer takes, so the CPU can't see it happen any sooner than on real hardware
+	for !m.copyHDMABlock() {
+	}
+}
+
+// copyHDMABlock copies 16 bytes to the current VRAM bank and returns true when the transfer is complete
+func (m *Mapper) copyHDMABlock() bool {
+	for i := 0; i < 0x10; i++ {
+		m.ppu.WriteVideoRAM(0x8000+m.hdmaDest, m.readForDMA(m.hdmaSource))
+		m.hdmaSource++
+		m.hdmaDest = (m.hdmaDest + 1) & 0x1fff
+	}
+	// Each block stalls the CPU for 8 machine cycles, or 16 of the shorter ones in double speed mode
+	if m.doubleSpeed {
+		m.hdmaStall += 16
+	} else {
+		m.hdmaStall += 8
+	}
+	if m.hdmaLength == 0 {
+		m.hdmaLength = 0x7f
+		return true
+	}
+	m.hdmaLength--
+	return false
+}
+
+// DumpRAM returns the contents of cart RAM
 func (m *Mapper) DumpRAM() []byte {
 	return m.mbc.DumpRAM()
 }
+
+// LoadRAM replaces the contents of cart RAM e.g. with data from a save file
+func (m *Mapper) LoadRAM(data []byte) {
+	m.mbc.LoadRAM(data)
+}
+
+// HasBattery returns true if the cart RAM is battery-backed
+func (m *Mapper) HasBattery() bool {
+	return m.battery
+}
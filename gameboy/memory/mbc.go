@@ -2,12 +2,16 @@ package memory
 
 import (
 	"fmt"
+	"io"
 )
 
 type mbc interface {
 	Read(addr uint16) uint8
 	Write(addr uint16, value uint8)
 	DumpRAM() []byte
+	LoadRAM(data []byte)
+	saveState(w io.Writer) error
+	loadState(r io.Reader) error
 }
 
 type none struct {
@@ -26,6 +30,10 @@ func (n *none) DumpRAM() []byte {
 	return []byte{}
 }
 
+func (n *none) LoadRAM(data []byte) {
+	// Do nothing
+}
+
 func newMBC(romImage []byte, rtc *rtc) mbc {
 
 	if romImage == nil || len(romImage) < 0x0148 {
@@ -113,6 +121,16 @@ func newMBC(romImage []byte, rtc *rtc) mbc {
 	panic(fmt.Sprintf("mbc does not support cart type 0x%02x", cartType))
 }
 
+// hasBattery returns true for cart types whose RAM is battery-backed and so should persist between sessions
+func hasBattery(cartType uint8) bool {
+	switch cartType {
+	case 0x03, 0x06, 0x09, 0x0d, 0x0f, 0x10, 0x13, 0x1b, 0x1e, 0x20, 0x22, 0xff:
+		return true
+	default:
+		return false
+	}
+}
+
 func prepareROM(romSize uint8, rom []byte) [][0x4000]byte {
 	if len(rom)%0x4000 != 0 {
 		panic(fmt.Sprintf("ROM size must be a multiple of 32KB. Current size: 0x%02x", len(rom)))
@@ -157,3 +175,18 @@ func prepareRAM(cartType, ramSize uint8) [][0x2000]byte {
 	}
 	return ram
 }
+
+func dumpBanks(ram [][0x2000]byte) []byte {
+	var dump []byte
+	for _, r := range ram {
+		dump = append(dump, r[:]...)
+	}
+	return dump
+}
+
+func loadBanks(ram [][0x2000]byte, data []byte) {
+	for i := 0; i < len(ram) && len(data) > 0; i++ {
+		n := copy(ram[i][:], data)
+		data = data[n:]
+	}
+}
@@ -101,9 +101,9 @@ func (m *mbc1) updateBanks() {
 }
 
 func (m *mbc1) DumpRAM() []byte {
-	var dump []byte
-	for _, r := range m.ram {
-		dump = append(dump, r[:]...)
-	}
-	return dump
+	return dumpBanks(m.ram)
+}
+
+func (m *mbc1) LoadRAM(data []byte) {
+	loadBanks(m.ram, data)
 }
@@ -68,3 +68,9 @@ func (m *mbc2) Write(addr uint16, value uint8) {
 func (m *mbc2) DumpRAM() []byte {
 	return m.ram
 }
+
+func (m *mbc2) LoadRAM(data []byte) {
+	for i := 0; i < len(m.ram) && i < len(data); i++ {
+		m.ram[i] = data[i] | 0xf0
+	}
+}
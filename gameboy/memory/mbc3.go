@@ -76,9 +76,9 @@ func (m *mbc3) Write(addr uint16, value uint8) {
 }
 
 func (m *mbc3) DumpRAM() []byte {
-	var dump []byte
-	for _, r := range m.ram {
-		dump = append(dump, r[:]...)
-	}
-	return dump
+	return dumpBanks(m.ram)
+}
+
+func (m *mbc3) LoadRAM(data []byte) {
+	loadBanks(m.ram, data)
 }
@@ -0,0 +1,272 @@
+package memory
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// mbc1State is the serialisable form of the MBC1 bank registers
+type mbc1State struct {
+	RAMEnabled bool
+	Bank1      uint8
+	Bank2      uint8
+	Mode1      bool
+}
+
+// mbc2State is the serialisable form of the MBC2 bank registers
+type mbc2State struct {
+	RAMEnabled bool
+	ROMBank    uint8
+}
+
+// mbc3State is the serialisable form of the MBC3 bank registers
+type mbc3State struct {
+	RAMEnabled bool
+	ROMBank    uint8
+	RAMBank    uint8
+}
+
+// mbc5State is the serialisable form of the MBC5 bank registers
+type mbc5State struct {
+	RAMEnabled bool
+	ROMBank    uint16
+	RAMBank    uint8
+}
+
+// rtcState is the serialisable form of the MBC3 real-time clock
+type rtcState struct {
+	S, M, H       uint8
+	D             uint16
+	Carry, Halt   bool
+	LS, LM, LH    uint8
+	LD            uint16
+	LCarry, LHalt bool
+	Ticks         uint32
+	Low           bool
+}
+
+// cgbState is the serialisable form of the CGB-only mapper registers
+type cgbState struct {
+	CGB         bool
+	SVBK        uint8
+	KEY1        uint8
+	DoubleSpeed bool
+	RTCPhase    bool
+	HDMASource  uint16
+	HDMADest    uint16
+	HDMALength  uint8
+	HDMAActive  bool
+	HDMAStall   uint16
+	PPUMode     uint8
+}
+
+// SaveState writes work RAM, high RAM, cart RAM, boot ROM mapping, CGB registers and the MBC and RTC registers
+func (m *Mapper) SaveState(w io.Writer) error {
+	for _, data := range [][]byte{m.internalRAM[:], m.zeroPage[:], m.mbc.DumpRAM()} {
+		_, err := w.Write(data)
+		if err != nil {
+			return err
+		}
+	}
+	err := binary.Write(w, binary.LittleEndian, m.bootMapped)
+	if err != nil {
+		return err
+	}
+	err = binary.Write(w, binary.LittleEndian, &cgbState{
+		CGB:         m.cgb,
+		SVBK:        m.svbk,
+		KEY1:        m.key1,
+		DoubleSpeed: m.doubleSpeed,
+		RTCPhase:    m.rtcPhase,
+		HDMASource:  m.hdmaSource,
+		HDMADest:    m.hdmaDest,
+		HDMALength:  m.hdmaLength,
+		HDMAActive:  m.hdmaActive,
+		HDMAStall:   uint16(m.hdmaStall),
+		PPUMode:     m.ppuMode,
+	})
+	if err != nil {
+		return err
+	}
+	err = m.mbc.saveState(w)
+	if err != nil {
+		return err
+	}
+	return m.rtc.saveState(w)
+}
+
+// LoadState restores work RAM, high RAM, cart RAM, boot ROM mapping, CGB registers and the MBC and RTC registers
+func (m *Mapper) LoadState(r io.Reader) error {
+	ram := make([]byte, len(m.mbc.DumpRAM()))
+	for _, data := range [][]byte{m.internalRAM[:], m.zeroPage[:], ram} {
+		_, err := io.ReadFull(r, data)
+		if err != nil {
+			return err
+		}
+	}
+	m.mbc.LoadRAM(ram)
+	var bootMapped bool
+	err := binary.Read(r, binary.LittleEndian, &bootMapped)
+	if err != nil {
+		return err
+	}
+	if bootMapped && m.bootROM == nil {
+		return errors.New("save state was made while the boot ROM was running but no boot ROM is configured")
+	}
+	var s cgbState
+	err = binary.Read(r, binary.LittleEndian, &s)
+	if err != nil {
+		return err
+	}
+	if s.CGB != m.cgb {
+		return errors.New("save state was made in a different hardware mode (DMG/CGB)")
+	}
+	m.bootMapped = bootMapped
+	m.svbk = s.SVBK
+	m.key1 = s.KEY1
+	m.doubleSpeed = s.DoubleSpeed
+	m.rtcPhase = s.RTCPhase
+	m.hdmaSource = s.HDMASource
+	m.hdmaDest = s.HDMADest
+	m.hdmaLength = s.HDMALength
+	m.hdmaActive = s.HDMAActive
+	m.hdmaStall = int(s.HDMAStall)
+	m.ppuMode = s.PPUMode
+	err = m.mbc.loadState(r)
+	if err != nil {
+		return err
+	}
+	return m.rtc.loadState(r)
+}
+
+func (n *none) saveState(w io.Writer) error {
+	return nil
+}
+
+func (n *none) loadState(r io.Reader) error {
+	return nil
+}
+
+func (m *mbc1) saveState(w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, &mbc1State{
+		RAMEnabled: m.ramEnabled,
+		Bank1:      m.bank1,
+		Bank2:      m.bank2,
+		Mode1:      m.mode1,
+	})
+}
+
+func (m *mbc1) loadState(r io.Reader) error {
+	var s mbc1State
+	err := binary.Read(r, binary.LittleEndian, &s)
+	if err != nil {
+		return err
+	}
+	m.ramEnabled = s.RAMEnabled
+	m.bank1 = s.Bank1
+	m.bank2 = s.Bank2
+	m.mode1 = s.Mode1
+	m.updateBanks()
+	return nil
+}
+
+func (m *mbc2) saveState(w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, &mbc2State{
+		RAMEnabled: m.ramEnabled,
+		ROMBank:    m.romBank,
+	})
+}
+
+func (m *mbc2) loadState(r io.Reader) error {
+	var s mbc2State
+	err := binary.Read(r, binary.LittleEndian, &s)
+	if err != nil {
+		return err
+	}
+	m.ramEnabled = s.RAMEnabled
+	m.romBank = s.ROMBank % uint8(len(m.rom))
+	return nil
+}
+
+func (m *mbc3) saveState(w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, &mbc3State{
+		RAMEnabled: m.ramEnabled,
+		ROMBank:    m.romBank,
+		RAMBank:    m.ramBank,
+	})
+}
+
+func (m *mbc3) loadState(r io.Reader) error {
+	var s mbc3State
+	err := binary.Read(r, binary.LittleEndian, &s)
+	if err != nil {
+		return err
+	}
+	m.ramEnabled = s.RAMEnabled
+	m.romBank = s.ROMBank
+	m.ramBank = s.RAMBank
+	return nil
+}
+
+func (m *mbc5) saveState(w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, &mbc5State{
+		RAMEnabled: m.ramEnabled,
+		ROMBank:    m.romBank,
+		RAMBank:    m.ramBank,
+	})
+}
+
+func (m *mbc5) loadState(r io.Reader) error {
+	var s mbc5State
+	err := binary.Read(r, binary.LittleEndian, &s)
+	if err != nil {
+		return err
+	}
+	m.ramEnabled = s.RAMEnabled
+	m.romBank = s.ROMBank % uint16(len(m.rom))
+	m.ramBank = s.RAMBank % uint8(len(m.ram))
+	return nil
+}
+
+func (r *rtc) saveState(w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, &rtcState{
+		S:      r.s,
+		M:      r.m,
+		H:      r.h,
+		D:      r.d,
+		Carry:  r.carry,
+		Halt:   r.halt,
+		LS:     r.ls,
+		LM:     r.lm,
+		LH:     r.lh,
+		LD:     r.ld,
+		LCarry: r.lcarry,
+		LHalt:  r.lhalt,
+		Ticks:  uint32(r.ticks),
+		Low:    r.low,
+	})
+}
+
+func (r *rtc) loadState(rd io.Reader) error {
+	var s rtcState
+	err := binary.Read(rd, binary.LittleEndian, &s)
+	if err != nil {
+		return err
+	}
+	r.s = s.S
+	r.m = s.M
+	r.h = s.H
+	r.d = s.D
+	r.carry = s.Carry
+	r.halt = s.Halt
+	r.ls = s.LS
+	r.lm = s.LM
+	r.lh = s.LH
+	r.ld = s.LD
+	r.lcarry = s.LCarry
+	r.lhalt = s.LHalt
+	r.ticks = int(s.Ticks)
+	r.low = s.Low
+	return nil
+}
@@ -0,0 +1,62 @@
+package gameboy
+
+import (
+	"fmt"
+
+	"github.com/scottyw/tetromino/gameboy/audio"
+)
+
+// configureAudio sets the master volume and chooses the output capacitor, which is the CGB's for CGB
+// games and the DMG's otherwise unless one is configured
+func configureAudio(a *audio.Audio, config Config, cgb bool) error {
+	if config.Volume != nil {
+		if *config.Volume < 0 || *config.Volume > 1 {
+			return fmt.Errorf("the volume %v is outside the range 0 to 1", *config.Volume)
+		}
+		a.SetVolume(float32(*config.Volume))
+	}
+	switch config.HighPassFilter {
+	case "":
+		if cgb {
+			a.SetHighPass(audio.CGBChargeFactor)
+		} else {
+			a.SetHighPass(audio.DMGChargeFactor)
+		}
+	case "dmg":
+		a.SetHighPass(audio.DMGChargeFactor)
+	case "cgb":
+		a.SetHighPass(audio.CGBChargeFactor)
+	case "off":
+	default:
+		return fmt.Errorf("unknown high-pass filter \"%s\"", config.HighPassFilter)
+	}
+	return nil
+}
+
+// MuteChannel silences an audio channel, numbered from 1 to 4, or brings it back
+func (gb *Gameboy) MuteChannel(channel int, muted bool) {
+	gb.audio.Mute(channel, muted)
+	gb.printChannels()
+}
+
+// SoloChannel picks out an audio channel, numbered from 1 to 4, or stops picking it out. While any
+// channel is soloed only the soloed channels are heard.
+func (gb *Gameboy) SoloChannel(channel int, soloed bool) {
+	gb.audio.Solo(channel, soloed)
+	gb.printChannels()
+}
+
+// printChannels shows which channels are muted and soloed e.g. "Channels: 1 2(muted) 3(solo) 4"
+func (gb *Gameboy) printChannels() {
+	s := "Channels:"
+	for channel := 1; channel <= 4; channel++ {
+		s += fmt.Sprintf(" %d", channel)
+		if gb.audio.Muted(channel) {
+			s += "(muted)"
+		}
+		if gb.audio.Soloed(channel) {
+			s += "(solo)"
+		}
+	}
+	fmt.Println(s)
+}
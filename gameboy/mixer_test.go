@@ -0,0 +1,41 @@
+package gameboy
+
+import (
+	"testing"
+
+	"github.com/scottyw/tetromino/gameboy/audio"
+)
+
+// volume returns a pointer to a configured volume
+func volume(v float64) *float64 {
+	return &v
+}
+
+func TestConfigureAudio(t *testing.T) {
+	tests := []struct {
+		config Config
+		cgb    bool
+		volume float32
+		valid  bool
+	}{
+		{config: Config{}, volume: audio.DefaultVolume, valid: true},
+		{config: Config{}, cgb: true, volume: audio.DefaultVolume, valid: true},
+		{config: Config{Volume: volume(0)}, volume: 0, valid: true},
+		{config: Config{Volume: volume(0.25), HighPassFilter: "cgb"}, volume: 0.25, valid: true},
+		{config: Config{Volume: volume(1), HighPassFilter: "off"}, volume: 1, valid: true},
+		{config: Config{Volume: volume(1.5)}},
+		{config: Config{Volume: volume(-0.5)}},
+		{config: Config{HighPassFilter: "agb"}},
+	}
+	for i, test := range tests {
+		a := audio.New(nil, nil, 0)
+		err := configureAudio(a, test.config, test.cgb)
+		if (err == nil) != test.valid {
+			t.Errorf("test %d: expected valid to be %v but found error %v", i, test.valid, err)
+			continue
+		}
+		if test.valid && a.Volume() != test.volume {
+			t.Errorf("test %d: expected volume %v but found %v", i, test.volume, a.Volume())
+		}
+	}
+}
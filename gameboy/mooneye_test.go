@@ -10,11 +10,15 @@ import (
 )
 
 func runMooneyeTest(t *testing.T, filename string) {
-	config := Config{
+	runMooneyeTestWithConfig(t, Config{
 		RomFilename:        filename,
 		DisableVideoOutput: true,
 		DisableAudioOutput: true,
-	}
+	})
+}
+
+func runMooneyeTestWithConfig(t *testing.T, config Config) {
+	filename := config.RomFilename
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	gameboy := New(config)
@@ -76,6 +80,7 @@ func TestMooneyeAcceptance(t *testing.T) {
 		"testdata/mts-20221022-1430-8d742b9/acceptance/ret_cc_timing.gb",
 		"testdata/mts-20221022-1430-8d742b9/acceptance/ret_timing.gb",
 		"testdata/mts-20221022-1430-8d742b9/acceptance/reti_timing.gb",
+		"testdata/mts-20221022-1430-8d742b9/acceptance/serial/boot_sclk_align-dmgABCmgb.gb",
 		// "testdata/mts-20221022-1430-8d742b9/acceptance/boot_hwio-dmgABCmgb.gb",
 		// "testdata/mts-20221022-1430-8d742b9/acceptance/call_cc_timing2.gb",
 		// "testdata/mts-20221022-1430-8d742b9/acceptance/call_timing2.gb",
@@ -91,7 +96,6 @@ func TestMooneyeAcceptance(t *testing.T) {
 		// "testdata/mts-20221022-1430-8d742b9/acceptance/rapid_di_ei.gb",
 		// "testdata/mts-20221022-1430-8d742b9/acceptance/reti_intr_timing.gb",
 		// "testdata/mts-20221022-1430-8d742b9/acceptance/rst_timing.gb",
-		// "testdata/mts-20221022-1430-8d742b9/acceptance/serial/boot_sclk_align-dmgABCmgb.gb",
 	} {
 		t.Run(filename, func(t *testing.T) {
 			runMooneyeTest(t, filename)
@@ -101,18 +105,18 @@ func TestMooneyeAcceptance(t *testing.T) {
 
 func TestMooneyePPU(t *testing.T) {
 	for _, filename := range []string{
-		// "testdata/mts-20221022-1430-8d742b9/acceptance/ppu/hblank_ly_scx_timing-GS.gb",
+		"testdata/mts-20221022-1430-8d742b9/acceptance/ppu/hblank_ly_scx_timing-GS.gb",
 		// "testdata/mts-20221022-1430-8d742b9/acceptance/ppu/intr_1_2_timing-GS.gb",
-		// "testdata/mts-20221022-1430-8d742b9/acceptance/ppu/intr_2_0_timing.gb",
-		// "testdata/mts-20221022-1430-8d742b9/acceptance/ppu/intr_2_mode0_timing_sprites.gb",
-		// "testdata/mts-20221022-1430-8d742b9/acceptance/ppu/intr_2_mode0_timing.gb",
-		// "testdata/mts-20221022-1430-8d742b9/acceptance/ppu/intr_2_mode3_timing.gb",
-		// "testdata/mts-20221022-1430-8d742b9/acceptance/ppu/intr_2_oam_ok_timing.gb",
-		// "testdata/mts-20221022-1430-8d742b9/acceptance/ppu/lcdon_timing-GS.gb",
+		"testdata/mts-20221022-1430-8d742b9/acceptance/ppu/intr_2_0_timing.gb",
+		"testdata/mts-20221022-1430-8d742b9/acceptance/ppu/intr_2_mode0_timing_sprites.gb",
+		"testdata/mts-20221022-1430-8d742b9/acceptance/ppu/intr_2_mode0_timing.gb",
+		"testdata/mts-20221022-1430-8d742b9/acceptance/ppu/intr_2_mode3_timing.gb",
+		"testdata/mts-20221022-1430-8d742b9/acceptance/ppu/intr_2_oam_ok_timing.gb",
+		"testdata/mts-20221022-1430-8d742b9/acceptance/ppu/lcdon_timing-GS.gb",
 		// "testdata/mts-20221022-1430-8d742b9/acceptance/ppu/lcdon_write_timing-GS.gb",
-		// "testdata/mts-20221022-1430-8d742b9/acceptance/ppu/stat_irq_blocking.gb",
-		// "testdata/mts-20221022-1430-8d742b9/acceptance/ppu/stat_lyc_onoff.gb",
-		// "testdata/mts-20221022-1430-8d742b9/acceptance/ppu/vblank_stat_intr-GS.gb",
+		"testdata/mts-20221022-1430-8d742b9/acceptance/ppu/stat_irq_blocking.gb",
+		"testdata/mts-20221022-1430-8d742b9/acceptance/ppu/stat_lyc_onoff.gb",
+		"testdata/mts-20221022-1430-8d742b9/acceptance/ppu/vblank_stat_intr-GS.gb",
 	} {
 		t.Run(filename, func(t *testing.T) {
 			runMooneyeTest(t, filename)
@@ -178,3 +182,19 @@ func TestMooneyeMBC(t *testing.T) {
 		})
 	}
 }
+
+func TestMooneyeBootROM(t *testing.T) {
+	for _, filename := range []string{
+		"testdata/mts-20221022-1430-8d742b9/acceptance/boot_div-dmgABCmgb.gb",
+		"testdata/mts-20221022-1430-8d742b9/acceptance/boot_regs-dmgABC.gb",
+	} {
+		t.Run(filename, func(t *testing.T) {
+			runMooneyeTestWithConfig(t, Config{
+				RomFilename:        filename,
+				BootROM:            true,
+				DisableVideoOutput: true,
+				DisableAudioOutput: true,
+			})
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package gameboy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/scottyw/tetromino/gameboy/controller"
+)
+
+// Movies start with a magic string and a format version followed by the hash
+// of the ROM and a save state of the machine at power-on. Playback restores
+// that state so that battery-backed RAM loaded at startup can't change the
+// outcome. The rest of the file is a list of button events terminated by an
+// end-of-movie event.
+const (
+	movieMagic   = "TETROMOV"
+	movieVersion = uint16(2)
+	endOfMovie   = uint8(0xff)
+)
+
+type movieHeader struct {
+	Magic       [8]byte
+	Version     uint16
+	ROMHash     uint32
+	StateLength uint32
+}
+
+// movieEvent is a button press or release on one of the joypads applied
+// immediately before the given machine cycle of the given frame
+type movieEvent struct {
+	Frame   uint32
+	Cycle   uint16
+	Player  uint8
+	Button  uint8
+	Pressed bool
+}
+
+type movieRecorder struct {
+	f *os.File
+	w *bufio.Writer
+}
+
+func newMovieRecorder(filename string, romHash uint32, state []byte) (*movieRecorder, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(f)
+	header := movieHeader{
+		Version:     movieVersion,
+		ROMHash:     romHash,
+		StateLength: uint32(len(state)),
+	}
+	copy(header.Magic[:], movieMagic)
+	err = binary.Write(w, binary.LittleEndian, &header)
+	if err == nil {
+		_, err = w.Write(state)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &movieRecorder{f: f, w: w}, nil
+}
+
+func (mr *movieRecorder) record(event movieEvent) {
+	err := binary.Write(mr.w, binary.LittleEndian, &event)
+	if err != nil {
+		fmt.Printf("Failed to record movie (%v)\n", err)
+	}
+}
+
+// close writes the end-of-movie event and closes the file
+func (mr *movieRecorder) close(frame uint64) error {
+	mr.record(movieEvent{Frame: uint32(frame), Button: endOfMovie})
+	err := mr.w.Flush()
+	if err != nil {
+		mr.f.Close()
+		return err
+	}
+	return mr.f.Close()
+}
+
+type moviePlayer struct {
+	events []movieEvent
+	next   int
+}
+
+// readMovie loads a movie, returning a player and the power-on state it was recorded from
+func readMovie(filename string, romHash uint32) (*moviePlayer, []byte, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	r := bytes.NewReader(data)
+	var header movieHeader
+	err = binary.Read(r, binary.LittleEndian, &header)
+	if err != nil {
+		return nil, nil, err
+	}
+	if string(header.Magic[:]) != movieMagic {
+		return nil, nil, errors.New("not a movie file")
+	}
+	if header.Version != movieVersion {
+		return nil, nil, fmt.Errorf("unsupported movie version: %d", header.Version)
+	}
+	if header.ROMHash != romHash {
+		return nil, nil, errors.New("movie was recorded with a different ROM")
+	}
+	state := make([]byte, header.StateLength)
+	_, err = io.ReadFull(r, state)
+	if err != nil {
+		return nil, nil, err
+	}
+	player := &moviePlayer{}
+	for {
+		var event movieEvent
+		err := binary.Read(r, binary.LittleEndian, &event)
+		if err != nil {
+			return nil, nil, fmt.Errorf("movie is truncated: %v", err)
+		}
+		player.events = append(player.events, event)
+		if event.Button == endOfMovie {
+			return player, state, nil
+		}
+	}
+}
+
+// apply presses and releases buttons for any events due before this machine cycle
+func (mp *moviePlayer) apply(gb *Gameboy, frame uint64, cycle int) {
+	for mp.next < len(mp.events) {
+		event := mp.events[mp.next]
+		if uint64(event.Frame) > frame || (uint64(event.Frame) == frame && int(event.Cycle) > cycle) {
+			return
+		}
+		if event.Button == endOfMovie {
+			return
+		}
+		mp.next++
+		gb.pressButton(int(event.Player), controller.Button(event.Button), event.Pressed)
+	}
+}
+
+// finished returns true once the movie has played up to the given frame
+func (mp *moviePlayer) finished(frame uint64) bool {
+	event := mp.events[mp.next]
+	return event.Button == endOfMovie && uint64(event.Frame) <= frame
+}
+
+// startMovie begins recording or playback of a movie as configured
+func (gb *Gameboy) startMovie() {
+	if gb.config.PlayMovie != "" {
+		player, state, err := readMovie(gb.config.PlayMovie, gb.romHash)
+		if err == nil {
+			err = gb.LoadState(bytes.NewReader(state))
+		}
+		if err != nil {
+			panic(fmt.Sprintf("Failed to play the movie at \"%s\" (%v)", gb.config.PlayMovie, err))
+		}
+		gb.player = player
+	}
+	if gb.config.RecordMovie != "" {
+		state := &bytes.Buffer{}
+		err := gb.SaveState(state)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to record the movie at \"%s\" (%v)", gb.config.RecordMovie, err))
+		}
+		gb.recorder, err = newMovieRecorder(gb.config.RecordMovie, gb.romHash, state.Bytes())
+		if err != nil {
+			panic(fmt.Sprintf("Failed to record the movie at \"%s\" (%v)", gb.config.RecordMovie, err))
+		}
+	}
+}
+
+// stopMovie finishes any movie recording in progress
+func (gb *Gameboy) stopMovie() {
+	if gb.recorder != nil {
+		err := gb.recorder.close(gb.frames)
+		if err != nil {
+			fmt.Printf("Failed to record the movie at \"%s\" (%v)\n", gb.config.RecordMovie, err)
+		}
+		gb.recorder = nil
+	}
+}
+
+// movieActive returns true if a movie is being recorded or played, in which case
+// anything that would break determinism such as loading state is disallowed
+func (gb *Gameboy) movieActive() bool {
+	return gb.recorder != nil || gb.player != nil
+}
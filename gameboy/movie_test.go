@@ -0,0 +1,192 @@
+package gameboy
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scottyw/tetromino/gameboy/controller"
+	"github.com/scottyw/tetromino/gameboy/sgb"
+)
+
+func TestMoviePlayback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "movie")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	movie := filepath.Join(dir, "test.mov")
+
+	// Record a few seconds of button presses
+	recording := New(Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		RecordMovie:        movie,
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	})
+	buttons := []controller.Button{controller.Start, controller.A, controller.Left, controller.Down}
+	for i, button := range buttons {
+		runFrames(recording, 20+i)
+		recording.onButton(button, true)
+		runFrames(recording, 5)
+		recording.onButton(button, false)
+	}
+	recording.onButton(controller.B, true)
+	runFrames(recording, 30)
+	recording.Cleanup()
+	expected := &bytes.Buffer{}
+	err = recording.SaveState(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedFrame := append([]byte(nil), recording.ppu.Frame().Pix...)
+
+	// Playback runs headless until the movie ends and must reproduce the same machine state
+	playback := New(Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		PlayMovie:          movie,
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	})
+	playback.Run(context.Background())
+	if playback.frames != recording.frames {
+		t.Errorf("expected %d frames but played %d", recording.frames, playback.frames)
+	}
+	actual := &bytes.Buffer{}
+	err = playback.SaveState(actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expected.Bytes(), actual.Bytes()) {
+		t.Error("machine state differs after movie playback")
+	}
+	if !bytes.Equal(expectedFrame, playback.ppu.Frame().Pix) {
+		t.Error("frame differs after movie playback")
+	}
+}
+
+func TestMovieRejectsOtherROM(t *testing.T) {
+	dir, err := ioutil.TempDir("", "movie")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	movie := filepath.Join(dir, "test.mov")
+	recording := New(Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		RecordMovie:        movie,
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	})
+	runFrames(recording, 10)
+	recording.Cleanup()
+
+	_, _, err = readMovie(movie, recording.romHash+1)
+	if err == nil {
+		t.Error("expected movie recorded with another ROM to be rejected")
+	}
+}
+
+func TestMovieMidFramePress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "movie")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	movie := filepath.Join(dir, "test.mov")
+
+	// Press Start part way through the second frame
+	recording := New(Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		RecordMovie:        movie,
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	})
+	runFrames(recording, 1)
+	for mtick := 0; mtick < 17556; mtick++ {
+		if mtick == 1000 {
+			recording.onButton(controller.Start, true)
+		}
+		recording.runMachineCycle(mtick)
+	}
+	recording.endFrame()
+	runFrames(recording, 2)
+	recording.Cleanup()
+
+	player, _, err := readMovie(movie, recording.romHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := movieEvent{Frame: 1, Cycle: 1000, Button: uint8(controller.Start), Pressed: true}
+	if player.events[0] != expected {
+		t.Fatalf("expected %+v but recorded %+v", expected, player.events[0])
+	}
+
+	// Playback presses Start immediately before the same machine cycle
+	playback := New(Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		PlayMovie:          movie,
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	})
+	runFrames(playback, 1)
+	for mtick := 0; mtick < 1000; mtick++ {
+		playback.runMachineCycle(mtick)
+	}
+	if playback.player.next != 0 {
+		t.Fatal("expected Start to be pressed at machine cycle 1000 but it was pressed earlier")
+	}
+	playback.runMachineCycle(1000)
+	if playback.player.next != 1 {
+		t.Fatal("expected Start to be pressed at machine cycle 1000")
+	}
+}
+
+func TestMovieMultiplayer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "movie")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	movie := filepath.Join(dir, "test.mov")
+	config := Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		SGB:                true,
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	}
+
+	// Record presses on the third joypad of an SGB with four joypads
+	config.RecordMovie = movie
+	recording := New(config)
+	sendPacket(recording, [16]byte{sgb.MLTREQ<<3 | 1, 0x03})
+	runFrames(recording, 10)
+	recording.PlayerButtonAction(2, controller.A, true)
+	runFrames(recording, 10)
+	recording.PlayerButtonAction(2, controller.Down, true)
+	runFrames(recording, 10)
+	recording.Cleanup()
+	expected := &bytes.Buffer{}
+	err = recording.SaveState(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Playback presses the same buttons on the same joypad
+	config.RecordMovie = ""
+	config.PlayMovie = movie
+	playback := New(config)
+	sendPacket(playback, [16]byte{sgb.MLTREQ<<3 | 1, 0x03})
+	playback.Run(context.Background())
+	actual := &bytes.Buffer{}
+	err = playback.SaveState(actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expected.Bytes(), actual.Bytes()) {
+		t.Error("machine state differs after movie playback")
+	}
+}
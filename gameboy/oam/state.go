@@ -0,0 +1,56 @@
+package oam
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// state is the serialisable form of OAM including any DMA transfer in progress
+type state struct {
+	OAM           [0xa0]byte
+	DMARunning    bool
+	DMACycle      uint16
+	DMABaseAddr   uint16
+	DMARead       uint8
+	Corrupt       bool
+	PPULastAccess uint16
+	Read          bool
+	Write         bool
+	DoubleWrite   bool
+}
+
+// SaveState writes the OAM state
+func (m *OAM) SaveState(w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, &state{
+		OAM:           m.oam,
+		DMARunning:    m.dmaRunning,
+		DMACycle:      m.dmaCycle,
+		DMABaseAddr:   m.dmaBaseAddr,
+		DMARead:       m.dmaRead,
+		Corrupt:       m.corrupt,
+		PPULastAccess: m.ppuLastAccess,
+		Read:          m.read,
+		Write:         m.write,
+		DoubleWrite:   m.doubleWrite,
+	})
+}
+
+// LoadState restores the OAM state
+func (m *OAM) LoadState(r io.Reader) error {
+	var s state
+	err := binary.Read(r, binary.LittleEndian, &s)
+	if err != nil {
+		return err
+	}
+	m.oam = s.OAM
+	m.dmaRunning = s.DMARunning
+	m.dmaCycle = s.DMACycle
+	m.dmaBaseAddr = s.DMABaseAddr
+	m.dmaRead = s.DMARead
+	m.corrupt = s.Corrupt
+	m.ppuLastAccess = s.PPULastAccess
+	m.read = s.Read
+	m.write = s.Write
+	m.doubleWrite = s.DoubleWrite
+	return nil
+}
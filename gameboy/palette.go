@@ -0,0 +1,51 @@
+package gameboy
+
+import (
+	"fmt"
+
+	"github.com/scottyw/tetromino/gameboy/ppu"
+)
+
+// loadPalettes sets up the palettes that the palette hotkey cycles through. A palette from the
+// config comes first, followed by the built-in palettes.
+func (gb *Gameboy) loadPalettes() {
+	gb.palettes = ppu.Palettes
+	var palette ppu.Palette
+	var err error
+	switch {
+	case gb.config.PaletteFile != "":
+		palette, err = ppu.ReadPaletteFile(gb.config.PaletteFile)
+	case gb.config.Palette != "":
+		palette, err = ppu.ParsePalette(gb.config.Palette)
+	default:
+		return
+	}
+	if err != nil {
+		panic(fmt.Sprintf("Failed to load the palette (%v)", err))
+	}
+	gb.palettes = append([]ppu.Palette{palette}, removePalette(ppu.Palettes, palette.Name)...)
+	gb.ppu.SetPalette(palette)
+}
+
+// removePalette returns the palettes without the one with the given name
+func removePalette(palettes []ppu.Palette, name string) []ppu.Palette {
+	var result []ppu.Palette
+	for _, palette := range palettes {
+		if palette.Name != name {
+			result = append(result, palette)
+		}
+	}
+	return result
+}
+
+// nextPalette switches to the next palette. Palettes only affect games running in DMG mode since
+// CGB games choose their own colours and the SGB colourises games itself.
+func (gb *Gameboy) nextPalette() {
+	if gb.ppu.CGB() || gb.sgb != nil {
+		fmt.Println("Palettes can only be changed for games running in DMG mode")
+		return
+	}
+	gb.palette = (gb.palette + 1) % len(gb.palettes)
+	gb.ppu.SetPalette(gb.palettes[gb.palette])
+	fmt.Printf("Palette: %s\n", gb.palettes[gb.palette].Name)
+}
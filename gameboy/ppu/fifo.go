@@ -0,0 +1,301 @@
+package ppu
+
+// Pixel transfer (mode 3) runs one dot at a time. A fetcher reads a tile from the background or
+// window map every 6 dots and pushes its 8 pixels into the background FIFO once the FIFO is empty.
+// Each dot shifts one pixel out of the FIFO onto the LCD, mixed with any sprite pixels in the
+// sprite FIFO. Mode 3 lasts 172 dots at minimum: the first tile fetch is thrown away and the second
+// takes another 6 dots before the first pixel appears, after an idle first dot. It gets longer by
+// the SCX fine scroll pixels that are shifted out and discarded at the start of the line, by 6 dots
+// when the window starts and the fetcher begins again, and by 6 to 11 dots for each sprite that's
+// fetched.
+
+// bgPixel is a background or window pixel waiting in the FIFO
+type bgPixel struct {
+	Colour     uint8
+	Attributes uint8
+	Window     bool
+}
+
+// objPixel is a sprite pixel waiting in the FIFO where colour 0 is transparent
+type objPixel struct {
+	Colour     uint8
+	Attributes uint8
+	Sprite     uint8
+}
+
+// pixelFIFO holds the state of the pixel transfer for the current line
+type pixelFIFO struct {
+	BG          [8]bgPixel
+	BGCount     uint8
+	Obj         [8]objPixel
+	FetchStep   uint8
+	FetchX      uint8
+	FetchTile   uint8
+	FetchAttrs  uint8
+	FetchLow    uint8
+	FetchHigh   uint8
+	FirstFetch  bool
+	Window      bool
+	Discard     uint8
+	X           uint8
+	Stall       uint8
+	Sprites     [40]uint8
+	SpriteCount uint8
+	PenaltyTile uint16
+}
+
+const (
+	// noPenaltyTile means no sprite has paid to wait for the background fetcher on this line yet
+	noPenaltyTile = 0xffff
+	// leftEdgeTile stands for the tile under sprites at X=0 which is off the left of the screen
+	leftEdgeTile = 0xfffe
+)
+
+// startTransfer begins mode 3 for the current line with the sprites found by the OAM search
+func (ppu *PPU) startTransfer() {
+	ppu.fifo = pixelFIFO{
+		FirstFetch:  true,
+		Discard:     ppu.scx & 0x07,
+		PenaltyTile: noPenaltyTile,
+		Stall:       1,
+	}
+	// Fine scrolling takes another 3 dots to get going before pixels are discarded
+	if ppu.scx&0x07 > 0 {
+		ppu.fifo.Stall += 3
+	}
+	for sprite, overlaps := range ppu.spriteOverlaps {
+		if overlaps {
+			ppu.fifo.Sprites[ppu.fifo.SpriteCount] = uint8(sprite)
+			ppu.fifo.SpriteCount++
+		}
+	}
+}
+
+// transferDot runs one dot of mode 3 and returns true once the last pixel of the line is drawn
+func (ppu *PPU) transferDot() bool {
+	f := &ppu.fifo
+
+	// Nothing moves while a sprite is being fetched
+	if f.Stall > 0 {
+		f.Stall--
+		return false
+	}
+
+	// Start the window when the next pixel is inside it. WX values from 0 to 6 start the window with
+	// its leftmost pixels off the screen and WX=166 shows just its first pixel at the right edge.
+	if !f.Window && ppu.windowEnabled && ppu.windowTriggered && ppu.wx <= 166 && f.X+7 >= ppu.wx {
+		f.Window = true
+		f.FetchStep = 0
+		f.FetchX = 0
+		f.BGCount = 0
+		f.Discard = 0
+		if ppu.wx < 7 {
+			f.Discard = 7 - ppu.wx
+		}
+	}
+
+	ppu.stepFetcher()
+	if f.BGCount == 0 {
+		return false
+	}
+
+	// Fetch a sprite if one starts at the next pixel
+	if ppu.spritesEnabled && f.Discard == 0 {
+		if i, ok := ppu.nextSprite(); ok {
+			sprite := f.Sprites[i]
+			spriteX := ppu.oam.PPURead(0xfe00 + uint16(sprite)*4 + 1)
+			f.Stall = ppu.spritePenalty(spriteX) - 1
+			ppu.fetchSprite(sprite)
+			copy(f.Sprites[i:], f.Sprites[i+1:f.SpriteCount])
+			f.SpriteCount--
+			return false
+		}
+	}
+
+	// Shift a pixel out onto the LCD
+	bg := f.BG[8-f.BGCount]
+	f.BGCount--
+	if f.Discard > 0 {
+		f.Discard--
+		return false
+	}
+	obj := f.Obj[0]
+	copy(f.Obj[:], f.Obj[1:])
+	f.Obj[7] = objPixel{}
+	ppu.outputPixel(f.X, ppu.ly, bg, obj)
+	f.X++
+	return f.X == 160
+}
+
+// nextSprite returns the position in the sprite list of the next sprite to fetch, if any sprite
+// starts at or before the next pixel. The CGB fetches such sprites in OAM order. The DMG fetches the
+// one with the lowest X coordinate first, so sprites are always fetched left to right.
+func (ppu *PPU) nextSprite() (uint8, bool) {
+	f := &ppu.fifo
+	var next uint8
+	var nextX uint8
+	found := false
+	for i := uint8(0); i < f.SpriteCount; i++ {
+		spriteX := ppu.oam.PPURead(0xfe00 + uint16(f.Sprites[i])*4 + 1)
+		if spriteX > f.X+8 {
+			continue
+		}
+		if ppu.cgb {
+			return i, true
+		}
+		if !found || spriteX < nextX {
+			next, nextX, found = i, spriteX, true
+		}
+	}
+	return next, found
+}
+
+// stepFetcher advances the background fetcher by one dot. It reads the tile number, then the low
+// and high bytes of the tile data two dots apart, and then waits to push the tile into the FIFO.
+func (ppu *PPU) stepFetcher() {
+	f := &ppu.fifo
+	if f.FetchStep == 6 && f.BGCount == 0 {
+		if f.FirstFetch {
+			// The first fetch of each line is thrown away and the same tile is fetched again
+			f.FirstFetch = false
+		} else {
+			ppu.pushTile()
+			f.FetchX++
+		}
+		f.FetchStep = 0
+	}
+	if f.FetchStep == 6 {
+		return
+	}
+	f.FetchStep++
+	switch f.FetchStep {
+	case 2:
+		ppu.fetchTileNumber()
+	case 4:
+		f.FetchLow = ppu.fetchTileData(0)
+	case 6:
+		f.FetchHigh = ppu.fetchTileData(1)
+	}
+}
+
+// fetchTileNumber reads the next tile number from the background or window map, along with the
+// tile attributes from VRAM bank 1 on the CGB
+//
+//	Bit 0-2  Background Palette number  (BGP0-7)
+//	Bit 3    Tile VRAM Bank number      (0=Bank 0, 1=Bank 1)
+//	Bit 5    Horizontal Flip            (0=Normal, 1=Mirror horizontally)
+//	Bit 6    Vertical Flip              (0=Normal, 1=Mirror vertically)
+//	Bit 7    BG-to-OAM Priority         (0=Use OAM priority bit, 1=BG Priority)
+func (ppu *PPU) fetchTileNumber() {
+	f := &ppu.fifo
+	var mapAddr uint16
+	if f.Window {
+		mapAddr = 0x9800 - 0x8000
+		if ppu.highWindowTileMap {
+			mapAddr = 0x9c00 - 0x8000
+		}
+		mapAddr += 32*(uint16(ppu.windowLine)/8) + uint16(f.FetchX&0x1f)
+	} else {
+		mapAddr = 0x9800 - 0x8000
+		if ppu.highBgTileMap {
+			mapAddr = 0x9c00 - 0x8000
+		}
+		mapAddr += 32*(uint16(ppu.ly+ppu.scy)/8) + uint16((ppu.scx>>3+f.FetchX)&0x1f)
+	}
+	f.FetchTile = ppu.videoRAM[mapAddr]
+	f.FetchAttrs = 0
+	if ppu.cgb {
+		f.FetchAttrs = ppu.videoRAM[0x2000+mapAddr]
+	}
+}
+
+// fetchTileData reads the low or high byte of the current row of the fetched tile
+func (ppu *PPU) fetchTileData(high uint16) uint8 {
+	f := &ppu.fifo
+	tileNumber := 256 + int(int8(f.FetchTile))
+	if ppu.lowTileData {
+		tileNumber = int(f.FetchTile)
+	}
+	row := (ppu.ly + ppu.scy) & 0x07
+	if f.Window {
+		row = ppu.windowLine & 0x07
+	}
+	if f.FetchAttrs&0x40 > 0 {
+		row = 7 - row
+	}
+	bank := int(f.FetchAttrs>>3) & 0x01
+	return ppu.videoRAM[bank*0x2000+tileNumber*16+int(row)*2+int(high)]
+}
+
+// pushTile decodes the fetched tile row into the background FIFO
+func (ppu *PPU) pushTile() {
+	f := &ppu.fifo
+	for i := range f.BG {
+		bit := uint(7 - i)
+		if f.FetchAttrs&0x20 > 0 {
+			bit = uint(i)
+		}
+		f.BG[i] = bgPixel{
+			Colour:     (f.FetchHigh>>bit&0x01)<<1 | f.FetchLow>>bit&0x01,
+			Attributes: f.FetchAttrs,
+			Window:     f.Window,
+		}
+	}
+	f.BGCount = 8
+}
+
+// spritePenalty returns how many dots a sprite fetch takes. Fetching a sprite takes 6 dots but the
+// sprite fetch first waits for the background fetcher to finish reading the tile under the sprite's
+// leftmost pixel. That wait is 5 dots less the pixel's position in the tile, and only the first
+// sprite over each tile waits. Sprites at X=0 are fetched before any background tile so the first
+// of them always waits the full 5 dots.
+func (ppu *PPU) spritePenalty(spriteX uint8) uint8 {
+	f := &ppu.fifo
+	var tile uint16
+	var offset uint8
+	switch {
+	case spriteX == 0:
+		tile = leftEdgeTile
+	case f.Window:
+		position := uint16(spriteX - ppu.wx - 1)
+		tile = 0x100 + position>>3
+		offset = uint8(position & 0x07)
+	default:
+		position := uint16(spriteX) + uint16(ppu.scx)
+		tile = position >> 3
+		offset = uint8(position & 0x07)
+	}
+	if tile == f.PenaltyTile {
+		return 6
+	}
+	f.PenaltyTile = tile
+	if offset >= 5 {
+		return 6
+	}
+	return 11 - offset
+}
+
+// fetchSprite mixes a sprite's pixels for this line into the sprite FIFO. Where sprites overlap on
+// the CGB the one earliest in OAM wins. On the DMG the one with the lowest X coordinate wins and
+// then the one earliest in OAM, which is always the sprite fetched first.
+func (ppu *PPU) fetchSprite(sprite uint8) {
+	f := &ppu.fifo
+	spriteAddr := 0xfe00 + uint16(sprite)*4
+	spriteX := ppu.oam.PPURead(spriteAddr + 1)
+	for i := uint8(0); i < 8; i++ {
+		// The slot in the FIFO for this pixel relative to the next pixel on the LCD
+		x := spriteX + i
+		if x < 8 || x-8 < f.X || x-8 >= f.X+8 {
+			continue
+		}
+		slot := x - 8 - f.X
+		colour, attributes := ppu.findSpritePixel(spriteAddr, x-8, ppu.ly)
+		if colour == 0 {
+			continue
+		}
+		existing := f.Obj[slot]
+		if existing.Colour == 0 || ppu.cgb && existing.Sprite > sprite {
+			f.Obj[slot] = objPixel{Colour: colour, Attributes: attributes, Sprite: sprite}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package ppu
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Palette holds the colours shown for the 4 DMG shades. The background and window, sprites using
+// OBP0 and sprites using OBP1 can each have their own colours like the CGB boot ROM gives DMG games.
+type Palette struct {
+	Name string
+	BG   [4]color.RGBA
+	OBP0 [4]color.RGBA
+	OBP1 [4]color.RGBA
+}
+
+// uniformPalette returns a palette using the same colours for the background and both sprite palettes
+func uniformPalette(name string, colours [4]color.RGBA) Palette {
+	return Palette{Name: name, BG: colours, OBP0: colours, OBP1: colours}
+}
+
+func rgb(hex uint32) color.RGBA {
+	return color.RGBA{uint8(hex >> 16), uint8(hex >> 8), uint8(hex), 0xff}
+}
+
+var (
+	cgbRed  = [4]color.RGBA{rgb(0xffffff), rgb(0xff8484), rgb(0x943a3a), rgb(0x000000)}
+	cgbBlue = [4]color.RGBA{rgb(0xffffff), rgb(0x63a5ff), rgb(0x0000ff), rgb(0x000000)}
+
+	// Palettes lists the built-in palettes in the order the palette hotkey cycles through them. The
+	// cgb palettes are a selection of those the CGB boot ROM lets the player choose for DMG games.
+	Palettes = []Palette{
+		uniformPalette("grey", grey),
+		uniformPalette("dmg", [4]color.RGBA{rgb(0x9bbc0f), rgb(0x8bac0f), rgb(0x306230), rgb(0x0f380f)}),
+		uniformPalette("pocket", [4]color.RGBA{rgb(0xc4cfa1), rgb(0x8b956d), rgb(0x4d533c), rgb(0x1f1f1f)}),
+		uniformPalette("cgb-brown", [4]color.RGBA{rgb(0xffffff), rgb(0xffad63), rgb(0x843100), rgb(0x000000)}),
+		uniformPalette("cgb-red", cgbRed),
+		{Name: "cgb-blue", BG: cgbBlue, OBP0: cgbRed, OBP1: cgbRed},
+		uniformPalette("cgb-green", [4]color.RGBA{rgb(0xffffff), rgb(0x52ff00), rgb(0xff4200), rgb(0x000000)}),
+		uniformPalette("cgb-inverted", [4]color.RGBA{rgb(0x000000), rgb(0x008484), rgb(0xffde00), rgb(0xffffff)}),
+	}
+)
+
+// ParsePalette returns the built-in palette with the given name, or a custom palette given as 4
+// comma-separated hex colours from lightest to darkest e.g. "e0f8d0,88c070,346856,081820"
+func ParsePalette(value string) (Palette, error) {
+	for _, palette := range Palettes {
+		if palette.Name == value {
+			return palette, nil
+		}
+	}
+	colours, err := parseColours(strings.Split(value, ","))
+	if err != nil {
+		return Palette{}, fmt.Errorf("palette %q is neither a built-in palette nor 4 colours: %v", value, err)
+	}
+	return uniformPalette("custom", colours), nil
+}
+
+// ReadPaletteFile reads a palette from a text file. Each line gives 4 hex colours from lightest to
+// darkest for "bg", "obp0", "obp1" or "all" of them, and lines starting with ";" are comments e.g.
+//
+//	; Blue background with red sprites
+//	bg   #ffffff #63a5ff #0000ff #000000
+//	obp0 #ffffff #ff8484 #943a3a #000000
+//	obp1 #ffffff #ff8484 #943a3a #000000
+//
+// Any of bg, obp0 and obp1 that aren't given use the grey palette.
+func ReadPaletteFile(filename string) (Palette, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return Palette{}, err
+	}
+	defer f.Close()
+	palette := Palettes[0]
+	palette.Name = filename
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+		colours, err := parseColours(fields[1:])
+		if err != nil {
+			return Palette{}, fmt.Errorf("%s:%d: %v", filename, line, err)
+		}
+		switch strings.ToLower(fields[0]) {
+		case "bg":
+			palette.BG = colours
+		case "obp0":
+			palette.OBP0 = colours
+		case "obp1":
+			palette.OBP1 = colours
+		case "all":
+			palette.BG, palette.OBP0, palette.OBP1 = colours, colours, colours
+		default:
+			return Palette{}, fmt.Errorf("%s:%d: unknown palette %q", filename, line, fields[0])
+		}
+	}
+	return palette, scanner.Err()
+}
+
+// parseColours parses exactly 4 colours written as 6 hex digits with an optional leading "#"
+func parseColours(values []string) ([4]color.RGBA, error) {
+	var colours [4]color.RGBA
+	if len(values) != 4 {
+		return colours, fmt.Errorf("expected 4 colours but found %d", len(values))
+	}
+	for i, value := range values {
+		value = strings.TrimPrefix(strings.TrimSpace(value), "#")
+		hex, err := strconv.ParseUint(value, 16, 32)
+		if err != nil || len(value) != 6 {
+			return colours, fmt.Errorf("invalid colour %q", values[i])
+		}
+		colours[i] = rgb(uint32(hex))
+	}
+	return colours, nil
+}
+
+// SetPalette changes the colours used to draw DMG frames
+func (ppu *PPU) SetPalette(palette Palette) {
+	ppu.palette = palette
+}
+
+// Palette returns the colours used to draw DMG frames
+func (ppu *PPU) Palette() Palette {
+	return ppu.palette
+}
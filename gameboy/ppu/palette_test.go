@@ -0,0 +1,86 @@
+package ppu
+
+import (
+	"image/color"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestParsePalette(t *testing.T) {
+	palette, err := ParsePalette("pocket")
+	if err != nil || palette.Name != "pocket" {
+		t.Errorf("expected the pocket palette but found %q (%v)", palette.Name, err)
+	}
+	palette, err = ParsePalette("#e0f8d0, 88c070,346856,081820")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if palette.BG[0] != rgb(0xe0f8d0) || palette.OBP1[3] != rgb(0x081820) {
+		t.Errorf("unexpected custom palette %v", palette)
+	}
+	for _, value := range []string{"purple", "e0f8d0,88c070,346856", "e0f8d0,88c070,346856,08182"} {
+		if _, err := ParsePalette(value); err == nil {
+			t.Errorf("expected palette %q to be rejected", value)
+		}
+	}
+}
+
+func writePaletteFile(t *testing.T, contents string) string {
+	f, err := ioutil.TempFile("", "palette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(contents); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func TestReadPaletteFile(t *testing.T) {
+	filename := writePaletteFile(t, "; Blue background\nbg #ffffff #63a5ff #0000ff #000000\n\nOBP1 ff0000 ff0000 ff0000 ff0000\n")
+	defer os.Remove(filename)
+	palette, err := ReadPaletteFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if palette.BG != cgbBlue {
+		t.Errorf("expected a blue background but found %v", palette.BG)
+	}
+	if palette.OBP0 != grey {
+		t.Errorf("expected grey OBP0 but found %v", palette.OBP0)
+	}
+	if palette.OBP1[2] != rgb(0xff0000) {
+		t.Errorf("expected red OBP1 but found %v", palette.OBP1)
+	}
+}
+
+func TestReadPaletteFileErrors(t *testing.T) {
+	for _, contents := range []string{"bg #ffffff #63a5ff #0000ff\n", "obp2 #ffffff #63a5ff #0000ff #000000\n"} {
+		filename := writePaletteFile(t, contents)
+		if _, err := ReadPaletteFile(filename); err == nil {
+			t.Errorf("expected palette file %q to be rejected", contents)
+		}
+		os.Remove(filename)
+	}
+}
+
+func TestPaletteColours(t *testing.T) {
+	ppu, m := newSpritePPU()
+	ppu.SetPalette(Palette{
+		BG:   [4]color.RGBA{rgb(0x000001), rgb(0x000002), rgb(0x000003), rgb(0x000004)},
+		OBP0: [4]color.RGBA{rgb(0x000100), rgb(0x000200), rgb(0x000300), rgb(0x000400)},
+		OBP1: [4]color.RGBA{rgb(0x010000), rgb(0x020000), rgb(0x030000), rgb(0x040000)},
+	})
+	ppu.WriteOBP1(0xe4)
+	writeSprite(m, 0, 8, 16, 2)
+	writeSprite(m, 1, 16, 16, 3)
+	m.Write(0xfe07, 0x10)
+	renderFrame(ppu, 0x93)
+	for x, expected := range map[int]color.RGBA{0: rgb(0x000300), 8: rgb(0x040000), 16: rgb(0x000001)} {
+		if actual := ppu.Frame().RGBAAt(x, 0); actual != expected {
+			t.Errorf("pixel (%d,0): expected %v but found %v", x, expected, actual)
+		}
+	}
+}
@@ -10,6 +10,9 @@ import (
 	"github.com/scottyw/tetromino/gameboy/oam"
 )
 
+// maxSpritesPerLine is the number of sprites the OAM search can select for a single line
+const maxSpritesPerLine = 10
+
 type PPU struct {
 
 	//LCDC
@@ -29,6 +32,7 @@ type PPU struct {
 	hlankInterrupt       bool
 	coincidence          bool
 	mode                 uint8
+	statMode             uint8
 
 	// BGP
 	bgpColour [4]uint8
@@ -47,18 +51,35 @@ type PPU struct {
 	wx  uint8
 	wy  uint8
 
+	// CGB registers and palette memory
+	vbk           uint8
+	bcps          uint8
+	ocps          uint8
+	bgPaletteRAM  [0x40]byte
+	objPaletteRAM [0x40]byte
+
 	// Internal state
-	interrupts     *interrupts.Interrupts
-	oam            *oam.OAM
-	videoRAM       [0x2000]byte
-	frame          *image.RGBA
-	spriteOverlaps [40]bool
-	ticks          int
-	firstLine      bool
-	debug          bool
+	interrupts      *interrupts.Interrupts
+	oam             *oam.OAM
+	cgb             bool
+	videoRAM        [0x4000]byte
+	frame           *image.RGBA
+	palette         Palette
+	shades          [144][160]uint8
+	spriteOverlaps  [40]bool
+	statLine        bool
+	windowLine      uint8
+	windowTriggered bool
+	fifo            pixelFIFO
+	ticks           int
+	lastMode        uint8
+	lineStart       bool
+	firstFrame      bool
+	firstLine       bool
+	debug           bool
 }
 
-func New(interrupts *interrupts.Interrupts, oam *oam.OAM, debug bool) *PPU {
+func New(interrupts *interrupts.Interrupts, oam *oam.OAM, cgb, debug bool) *PPU {
 	var frame *image.RGBA
 	if debug {
 		frame = image.NewRGBA(image.Rect(0, 0, 256, 256))
@@ -69,6 +90,8 @@ func New(interrupts *interrupts.Interrupts, oam *oam.OAM, debug bool) *PPU {
 		interrupts: interrupts,
 		oam:        oam,
 		frame:      frame,
+		palette:    Palettes[0],
+		cgb:        cgb,
 		debug:      debug,
 	}
 	ppu.WriteLCDC(0x91)
@@ -82,9 +105,21 @@ func New(interrupts *interrupts.Interrupts, oam *oam.OAM, debug bool) *PPU {
 	ppu.WriteBGP(0xFC)
 	ppu.WriteOBP0(0xFF)
 	ppu.WriteOBP1(0xFF)
+	// The CGB boot ROM leaves every background colour white
+	for i := range ppu.bgPaletteRAM {
+		ppu.bgPaletteRAM[i] = 0xff
+	}
 	return ppu
 }
 
+// PowerOn resets the PPU to its state before the boot ROM runs
+func (ppu *PPU) PowerOn() {
+	ppu.WriteLCDC(0x00)
+	ppu.WriteBGP(0x00)
+	ppu.WriteOBP0(0x00)
+	ppu.WriteOBP1(0x00)
+}
+
 // EndMachineCycle updates the LCD driver after each machine cycle i.e. 4 clock cycles
 func (ppu *PPU) EndMachineCycle() {
 
@@ -93,6 +128,16 @@ func (ppu *PPU) EndMachineCycle() {
 		return
 	}
 
+	// During the first frame after the LCD is switched on, STAT and the CPU's access to OAM and VRAM
+	// follow the mode a machine cycle late and STAT reports mode 0 for the first machine cycle of the
+	// OAM search
+	previous := ppu.lastMode
+	ppu.lastMode = ppu.mode
+	ppu.statMode = ppu.mode
+	if ppu.mode == 2 && previous != 2 {
+		ppu.statMode = 0
+	}
+
 	// Find x and y co-ords
 	ppu.ly = uint8(ppu.ticks / 114)
 	ticksThisLine := uint8(ppu.ticks % 114)
@@ -100,91 +145,106 @@ func (ppu *PPU) EndMachineCycle() {
 	// Should we switch to a different mode?
 	switch ppu.mode {
 	case 2:
+		if ticksThisLine == 0 {
+			ppu.enterMode2()
+		}
 		if ticksThisLine == 20 {
 			ppu.mode = 3
 			ppu.oam.ExitMode2()
+			ppu.startTransfer()
 		}
 	case 3:
-		// In reality, this time is dependent on sprite reading delays
-		// but we are hardcoding to the minimum for now i.e. 41 mticks
-		if ticksThisLine == 61 {
-			ppu.mode = 0
-			// If the h-blank interrupt is enabled in stat
-			// then the stat interrupt occurs
-			if ppu.hlankInterrupt {
-				ppu.interrupts.RequestStat()
-			}
-		}
+		// Mode 3 ends when the pixel transfer reaches the end of the line
 	case 0:
-		// In reality, this time is dependent on the time spent in mode 3
-		// but we are hardcoding to the maximum for now i.e. 52
-		if ticksThisLine == 0 {
-			if ppu.ly == 144 {
-				ppu.mode = 1
-				// V-blank interrupt always occurs
-				ppu.interrupts.RequestVblank()
-				// If the v-blank interrupt is also enabled in stat
-				// then the stat interrupt occurs too
-				if ppu.vblankInterrupt {
-					ppu.interrupts.RequestStat()
-				}
-			} else {
-				ppu.mode = 2
-				ppu.oam.EnterMode2()
-				// If the oam interrupt is enabled in stat
-				// then the stat interrupt occurs
-				if ppu.oamInterrupt {
-					ppu.interrupts.RequestStat()
-				}
-			}
+		// The first line after the LCD is switched on has no OAM search and STAT reports mode 0 until
+		// the pixel transfer starts
+		if ppu.firstLine && ticksThisLine == 18 {
+			ppu.mode = 3
+			ppu.spriteOverlaps = [40]bool{}
+			ppu.startTransfer()
+		}
+		// H-blank lasts for whatever is left of the line after mode 3
+		if ticksThisLine == 0 && ppu.ly == 144 {
+			ppu.mode = 1
+			// V-blank interrupt always occurs
+			ppu.interrupts.RequestVblank()
+		}
+		// Mode 2 starts a machine cycle before LY moves on to the next line
+		if ticksThisLine == 113 && ppu.ly < 143 {
+			ppu.mode = 2
 		}
 	case 1:
 		if ppu.ticks == 0 {
 			ppu.mode = 2
-			ppu.oam.EnterMode2()
+			ppu.enterMode2()
 		}
 	default:
 		panic(fmt.Sprintf("unexpected mode during check: %d", ppu.mode))
 	}
 
-	// Check coincidence flag
+	// Check coincidence flag, although STAT reports it clear for the first machine cycle of each line
+	ppu.lineStart = ticksThisLine == 0
 	if ticksThisLine == 0 {
 		ppu.coincidence = ppu.ly == ppu.lyc
-		// If the coincidence interrupt is enabled in stat
-		// then the stat interrupt occurs
-		if ppu.coincidence && ppu.coincidenceInterrupt {
-			ppu.interrupts.RequestStat()
+	}
+
+	// The window can only appear once LY has matched WY at the start of a line, after which it stays
+	// available for the rest of the frame even if WY changes
+	if ticksThisLine == 0 {
+		if ppu.ly == 0 {
+			ppu.windowTriggered = false
+			ppu.windowLine = 0
+		}
+		if ppu.ly == ppu.wy {
+			ppu.windowTriggered = true
 		}
 	}
 
 	// Execute a single tick
 	switch ppu.mode {
 	case 2:
-		ppu.checkOverlappingSprites(ticksThisLine)
+		// The OAM search checks 2 sprites per machine cycle
+		if ticksThisLine < 20 {
+			ppu.checkOverlappingSprites(ticksThisLine)
+		}
 	case 3:
-		lx := (ticksThisLine - 20) * 4
-		if lx < 160 {
-			ppu.renderPixel(lx, ppu.ly)
-			ppu.renderPixel(lx+1, ppu.ly)
-			ppu.renderPixel(lx+2, ppu.ly)
-			ppu.renderPixel(lx+3, ppu.ly)
+		// Each machine cycle is 4 dots
+		for dot := 0; dot < 4; dot++ {
+			if ppu.transferDot() {
+				ppu.mode = 0
+				// The window's own line counter only moves on when the window was drawn on this line
+				if ppu.fifo.Window {
+					ppu.windowLine++
+				}
+				// The first line after being enabled is 2 ticks shorter
+				if ppu.firstLine {
+					ppu.ticks += 2
+					ppu.firstLine = false
+				}
+				break
+			}
 		}
 	case 0:
-		// The first line after being enabled, the h-blank period is 2 ticks shorter
-		if ppu.firstLine {
-			ppu.ticks += 2
-			ppu.firstLine = false
-		}
+		// Nothing to do
 	case 1:
 		// Nothing to do
 	default:
 		panic(fmt.Sprintf("unexpected mode during tick: %d", ppu.mode))
 	}
 
+	// After the first frame they follow the mode directly
+	if !ppu.firstFrame {
+		ppu.lastMode = ppu.mode
+		ppu.statMode = ppu.mode
+	}
+
+	ppu.updateStatLine()
+
 	// Tick the PPU
 	ppu.ticks++
 	if ppu.ticks == 17556 {
 		ppu.ticks = 0
+		ppu.firstFrame = false
 	}
 
 }
@@ -194,17 +254,65 @@ func (ppu *PPU) checkOverlappingSprites(lx uint8) {
 	ppu.checkOverlappingSprite((lx * 2) + 1)
 }
 
+// checkOverlappingSprite marks a sprite as appearing on the current line. The OAM search selects at
+// most 10 sprites per line in OAM order, and sprites count towards the limit even when their X
+// coordinate puts them off screen.
 func (ppu *PPU) checkOverlappingSprite(sprite uint8) {
+	ppu.spriteOverlaps[sprite] = false
+	var selected int
+	for _, overlaps := range ppu.spriteOverlaps[:sprite] {
+		if overlaps {
+			selected++
+		}
+	}
+	if selected >= maxSpritesPerLine {
+		return
+	}
 	spriteAddr := 0xfe00 + uint16(sprite*4)
 	startY := ppu.oam.PPURead(spriteAddr)
-	ppu.spriteOverlaps[sprite] = startY != 0 && ppu.ly >= startY-16 && ppu.ly < startY-8
+	row := int(ppu.ly) + 16 - int(startY)
+	ppu.spriteOverlaps[sprite] = row >= 0 && row < ppu.spriteHeight()
+}
+
+// spriteHeight returns the height of sprites selected by LCDC bit 2
+func (ppu *PPU) spriteHeight() int {
+	if ppu.spritesLarge {
+		return 16
+	}
+	return 8
+}
+
+// updateStatLine recalculates the STAT interrupt line, which is high while any enabled STAT source is
+// active. The STAT interrupt is only requested when the line goes from low to high so a source
+// that becomes active while another is already holding the line high is blocked. The mode 2 source
+// is also active for the first machine cycle of line 144 as v-blank starts.
+func (ppu *PPU) updateStatLine() {
+	line := ppu.enabled && (ppu.coincidenceInterrupt && ppu.coincidence ||
+		ppu.oamInterrupt && (ppu.lastMode == 2 || ppu.ticks == 144*114) ||
+		ppu.vblankInterrupt && ppu.mode == 1 ||
+		ppu.hlankInterrupt && ppu.lastMode == 0)
+	if line && !ppu.statLine {
+		ppu.interrupts.RequestStat()
+	}
+	ppu.statLine = line
+}
+
+// enterMode2 starts the OAM search during which the DMG corrupts OAM on some accesses
+func (ppu *PPU) enterMode2() {
+	if !ppu.cgb {
+		ppu.oam.EnterMode2()
+	}
 }
 
 func (ppu *PPU) enable() {
 	ppu.enabled = true
 	ppu.firstLine = true
-	ppu.mode = 2
-	ppu.oam.EnterMode2()
+	ppu.firstFrame = true
+	ppu.mode = 0
+	ppu.statMode = 0
+	ppu.lastMode = 0
+	ppu.coincidence = ppu.ly == ppu.lyc
+	ppu.updateStatLine()
 }
 
 func (ppu *PPU) disable() {
@@ -212,14 +320,61 @@ func (ppu *PPU) disable() {
 	ppu.ly = 0
 	ppu.ticks = 0
 	ppu.mode = 0
+	ppu.statMode = 0
+	ppu.lastMode = 0
 }
 
 func (ppu *PPU) ReadVideoRAM(addr uint16) uint8 {
-	return ppu.videoRAM[addr-0x8000]
+	return ppu.videoRAM[uint16(ppu.vbk)*0x2000+addr-0x8000]
 }
 
 func (ppu *PPU) WriteVideoRAM(addr uint16, value uint8) {
-	ppu.videoRAM[addr-0x8000] = value
+	ppu.videoRAM[uint16(ppu.vbk)*0x2000+addr-0x8000] = value
+}
+
+// Mode returns the current PPU mode as reported by STAT
+func (ppu *PPU) Mode() uint8 {
+	return ppu.mode
+}
+
+// OAMBlocked returns true while the CPU can't access OAM because the PPU is using it
+func (ppu *PPU) OAMBlocked() bool {
+	return ppu.lastMode == 2 || ppu.lastMode == 3
+}
+
+// VRAMBlocked returns true while the CPU can't access VRAM because the PPU is using it. Unlike OAM
+// and STAT, VRAM is blocked as soon as the pixel transfer starts.
+func (ppu *PPU) VRAMBlocked() bool {
+	return ppu.lastMode == 3 || ppu.mode == 3 && ppu.lastMode == 2
+}
+
+// CGB returns true when the PPU is running in CGB mode
+func (ppu *PPU) CGB() bool {
+	return ppu.cgb
+}
+
+// Shade returns the DMG shade from 0 (lightest) to 3 (darkest) of a pixel in the most recent frame
+func (ppu *PPU) Shade(x, y int) uint8 {
+	return ppu.shades[y][x]
+}
+
+// VRAMTransfer returns the 4KB of tile data currently shown on screen. The SGB receives data for its
+// VRAM transfer commands this way, reading the first 256 background tiles in screen order.
+func (ppu *PPU) VRAMTransfer() []byte {
+	var mapAddr uint16 = 0x9800 - 0x8000
+	if ppu.highBgTileMap {
+		mapAddr = 0x9c00 - 0x8000
+	}
+	data := make([]byte, 0, 0x1000)
+	for i := uint16(0); i < 256; i++ {
+		tileByte := ppu.videoRAM[mapAddr+32*(i/20)+i%20]
+		tileNumber := 256 + int(int8(tileByte))
+		if ppu.lowTileData {
+			tileNumber = int(tileByte)
+		}
+		data = append(data, ppu.videoRAM[tileNumber*16:tileNumber*16+16]...)
+	}
+	return data
 }
 
 // Frame returns the most recently rendered frame
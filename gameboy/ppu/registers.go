@@ -77,6 +77,13 @@ func (ppu *PPU) ReadLCDC() uint8 {
 // WriteSTAT handles writes to register STAT
 func (ppu *PPU) WriteSTAT(value uint8) {
 	// fmt.Printf("> STAT - 0x%02x\n", value)
+	ppu.writeSTATEnables(value)
+	// Enabling a source that's already active raises the STAT line straight away
+	ppu.updateStatLine()
+}
+
+// writeSTATEnables sets which sources drive the STAT interrupt line
+func (ppu *PPU) writeSTATEnables(value uint8) {
 	ppu.coincidenceInterrupt = value&0x40 > 0
 	ppu.oamInterrupt = value&0x20 > 0
 	ppu.vblankInterrupt = value&0x10 > 0
@@ -99,10 +106,10 @@ func (ppu *PPU) ReadSTAT() uint8 {
 	if ppu.hlankInterrupt {
 		stat += 0x08
 	}
-	if ppu.coincidence {
+	if ppu.coincidence && !ppu.lineStart {
 		stat += 0x04
 	}
-	stat += ppu.mode
+	stat += ppu.statMode
 	// fmt.Printf("< STAT - 0x%02x\n", stat)
 	return stat
 }
@@ -169,6 +176,11 @@ func (ppu *PPU) ReadLY() uint8 {
 func (ppu *PPU) WriteLYC(value uint8) {
 	// fmt.Printf("> LYC - 0x%02x\n", value)
 	ppu.lyc = value
+	// The comparison is continuous so changing LYC can raise the STAT line straight away
+	if ppu.enabled {
+		ppu.coincidence = ppu.ly == ppu.lyc
+		ppu.updateStatLine()
+	}
 }
 
 // ReadLYC handles reads from register LYC
@@ -293,3 +305,96 @@ func (ppu *PPU) ReadOBP1() uint8 {
 	// fmt.Printf("< OBP1 - 0x%02x\n", obp1)
 	return obp1
 }
+
+// FF4F - VBK - CGB Mode Only - VRAM Bank
+// This register can be written to change VRAM banks. Only bit 0 matters, all
+// other bits are ignored.
+// Bank 1 contains a second set of tile data and the background map attributes.
+
+// WriteVBK handles writes to register VBK
+func (ppu *PPU) WriteVBK(value uint8) {
+	// fmt.Printf("> VBK - 0x%02x\n", value)
+	ppu.vbk = value & 0x01
+}
+
+// ReadVBK handles reads from register VBK
+func (ppu *PPU) ReadVBK() uint8 {
+	vbk := ppu.vbk | 0xfe
+	// fmt.Printf("< VBK - 0x%02x\n", vbk)
+	return vbk
+}
+
+// FF68 - BCPS/BGPI - CGB Mode Only - Background Palette Index
+// Bit 7     Auto Increment  (0=Disabled, 1=Increment after Writing)
+// Bit 5-0   Index (00-3F)
+// Each of the 8 palettes has 4 colours of 2 bytes each in little-endian
+// RGB555 format i.e. Bit 0-4 Red, Bit 5-9 Green, Bit 10-14 Blue.
+
+// WriteBCPS handles writes to register BCPS
+func (ppu *PPU) WriteBCPS(value uint8) {
+	// fmt.Printf("> BCPS - 0x%02x\n", value)
+	ppu.bcps = value & 0xbf
+}
+
+// ReadBCPS handles reads from register BCPS
+func (ppu *PPU) ReadBCPS() uint8 {
+	bcps := ppu.bcps | 0x40
+	// fmt.Printf("< BCPS - 0x%02x\n", bcps)
+	return bcps
+}
+
+// FF69 - BCPD/BGPD - CGB Mode Only - Background Palette Data
+// Reads and writes the palette memory addressed by BCPS.
+
+// WriteBCPD handles writes to register BCPD
+func (ppu *PPU) WriteBCPD(value uint8) {
+	// fmt.Printf("> BCPD - 0x%02x\n", value)
+	ppu.bgPaletteRAM[ppu.bcps&0x3f] = value
+	ppu.bcps = incrementPaletteIndex(ppu.bcps)
+}
+
+// ReadBCPD handles reads from register BCPD
+func (ppu *PPU) ReadBCPD() uint8 {
+	bcpd := ppu.bgPaletteRAM[ppu.bcps&0x3f]
+	// fmt.Printf("< BCPD - 0x%02x\n", bcpd)
+	return bcpd
+}
+
+// FF6A - OCPS/OBPI - CGB Mode Only - Sprite Palette Index
+// FF6B - OCPD/OBPD - CGB Mode Only - Sprite Palette Data
+// These registers work exactly like BCPS and BCPD but for the sprite palettes.
+
+// WriteOCPS handles writes to register OCPS
+func (ppu *PPU) WriteOCPS(value uint8) {
+	// fmt.Printf("> OCPS - 0x%02x\n", value)
+	ppu.ocps = value & 0xbf
+}
+
+// ReadOCPS handles reads from register OCPS
+func (ppu *PPU) ReadOCPS() uint8 {
+	ocps := ppu.ocps | 0x40
+	// fmt.Printf("< OCPS - 0x%02x\n", ocps)
+	return ocps
+}
+
+// WriteOCPD handles writes to register OCPD
+func (ppu *PPU) WriteOCPD(value uint8) {
+	// fmt.Printf("> OCPD - 0x%02x\n", value)
+	ppu.objPaletteRAM[ppu.ocps&0x3f] = value
+	ppu.ocps = incrementPaletteIndex(ppu.ocps)
+}
+
+// ReadOCPD handles reads from register OCPD
+func (ppu *PPU) ReadOCPD() uint8 {
+	ocpd := ppu.objPaletteRAM[ppu.ocps&0x3f]
+	// fmt.Printf("< OCPD - 0x%02x\n", ocpd)
+	return ocpd
+}
+
+// incrementPaletteIndex moves a palette index register on after a write if auto increment is enabled
+func incrementPaletteIndex(index uint8) uint8 {
+	if index&0x80 == 0 {
+		return index
+	}
+	return 0x80 | (index+1)&0x3f
+}
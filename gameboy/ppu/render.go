@@ -5,41 +5,28 @@ import (
 )
 
 var (
-
-	// Tile bits are counted left-to-right so bit 0 of the tile is bit 7 of the byte
-	patterns = []uint8{
-		0b10000000,
-		0b01000000,
-		0b00100000,
-		0b00010000,
-		0b00001000,
-		0b00000100,
-		0b00000010,
-		0b00000001,
-	}
-
-	grey = []color.RGBA{
+	grey = [4]color.RGBA{
 		{0xff, 0xff, 0xff, 0xff},
 		{0xaa, 0xaa, 0xaa, 0xff},
 		{0x77, 0x77, 0x77, 0xff},
 		{0x33, 0x33, 0x33, 0xff},
 	}
 
-	// red = []color.RGBA{
+	// red = [4]color.RGBA{
 	// 	{0xff, 0xaa, 0xaa, 0xff},
 	// 	{0xdd, 0x77, 0x77, 0xff},
 	// 	{0xaa, 0x33, 0x33, 0xff},
 	// 	{0x55, 0x00, 0x00, 0xff},
 	// }
 
-	green = []color.RGBA{
+	green = [4]color.RGBA{
 		{0xaa, 0xff, 0xaa, 0xff},
 		{0x77, 0xdd, 0x77, 0xff},
 		{0x33, 0xaa, 0x33, 0xff},
 		{0x00, 0x55, 0x00, 0xff},
 	}
 
-	blue = []color.RGBA{
+	blue = [4]color.RGBA{
 		{0xaa, 0xaa, 0xff, 0xff},
 		{0x77, 0x77, 0xdd, 0xff},
 		{0x33, 0x33, 0xaa, 0xff},
@@ -47,174 +34,111 @@ var (
 	}
 )
 
-func (ppu *PPU) renderPixel(x, y uint8) {
+// outputPixel draws a pixel shifted out of the FIFOs, mixing the background and sprite pixels. The
+// palettes are applied now so that mid-line palette changes take effect at the right pixel.
+func (ppu *PPU) outputPixel(x, y uint8, bg bgPixel, obj objPixel) {
 
-	colours := grey
-
-	var spritePixel uint8
-	var spriteBehindBackground bool
-	var useSpritePalette1 bool
-
-	// Does this pixel intersect with a sprite?
-	if ppu.spritesEnabled {
+	if ppu.cgb {
+		ppu.outputPixelCGB(x, y, bg, obj)
+		return
+	}
 
-		for sprite, overlaps := range ppu.spriteOverlaps {
-			if !overlaps {
-				continue
-			}
-			spriteAddr := 0xfe00 + uint16(sprite*4)
-			spriteX := ppu.oam.PPURead(spriteAddr + 1)
-			if x+8 >= spriteX && x < spriteX {
-				spriteY := ppu.oam.PPURead(spriteAddr)
-				tileNumber := ppu.oam.PPURead(spriteAddr + 2)
-				attributes := ppu.oam.PPURead(spriteAddr + 3)
-				tileOffsetX := (x - spriteX) % 8
-				tileOffsetY := (y - spriteY) % 8
-				spriteBehindBackground = attributes&0x80 > 0
-				flipY := attributes&0x40 > 0
-				flipX := attributes&0x20 > 0
-				useSpritePalette1 = attributes&0x08 > 0
-				if flipX {
-					tileOffsetX = 7 - tileOffsetX
-				}
-				if flipY {
-					tileOffsetY = 7 - tileOffsetY
-				}
-				spritePixel = ppu.readTilePixel(int(tileNumber), tileOffsetX, tileOffsetY)
-				if spritePixel > 0 {
-					break
-				}
-			}
-		}
+	// LCDC bit 0 blanks both the background and the window on the DMG
+	if !ppu.bgEnabled {
+		bg.Colour = 0
+	}
 
-		// Did we intersect with a foreground sprite?
-		if spritePixel > 0 && !spriteBehindBackground {
+	// Sprite pixels show unless they're behind a non-zero background pixel
+	if ppu.spritesEnabled && obj.Colour > 0 && (obj.Attributes&0x80 == 0 || bg.Colour == 0) {
+		if obj.Attributes&0x10 > 0 {
+			colours := ppu.palette.OBP1
 			if ppu.debug {
 				colours = blue
 			}
-			if useSpritePalette1 {
-				ppu.frame.SetRGBA(int(x), int(y), colours[ppu.obp1Colour[spritePixel]])
-			} else {
-				ppu.frame.SetRGBA(int(x), int(y), colours[ppu.obp0Colour[spritePixel]])
+			ppu.setShade(x, y, colours, ppu.obp1Colour[obj.Colour])
+		} else {
+			colours := ppu.palette.OBP0
+			if ppu.debug {
+				colours = blue
 			}
-			return
+			ppu.setShade(x, y, colours, ppu.obp0Colour[obj.Colour])
 		}
-
+		return
 	}
 
-	var pixel uint8
-
-	// Does this pixel intersect with the window?
-	var intersectedWindow bool
-	if ppu.windowEnabled {
-		wx := ppu.ReadWX()
-		wy := ppu.ReadWY()
-		if wx <= 166 && wy <= 143 && x >= (wx-7) && y >= wy {
-			pixel = ppu.findWindowPixel(x-(wx-7), y-wy)
-			intersectedWindow = true
-		}
+	colours := ppu.palette.BG
+	if bg.Window && ppu.debug {
+		colours = green
 	}
+	ppu.setShade(x, y, colours, ppu.bgpColour[bg.Colour])
 
-	// If we didn't find a window pixel then find where this pixel intersects the background
-	if !intersectedWindow && ppu.bgEnabled {
-		pixel = ppu.findBackgroundPixel(x, y)
-	}
+}
 
-	// Does this pixel intersect with a background sprite?
-	if pixel == 0 && spritePixel != 0 && spriteBehindBackground {
-		if ppu.debug {
-			colours = blue
-		}
-		if useSpritePalette1 {
-			ppu.frame.SetRGBA(int(x), int(y), colours[ppu.obp1Colour[spritePixel]])
-		} else {
-			ppu.frame.SetRGBA(int(x), int(y), colours[ppu.obp0Colour[spritePixel]])
-		}
-	} else {
-		if intersectedWindow {
-			if ppu.debug {
-				colours = green
-			}
-			ppu.frame.SetRGBA(int(x), int(y), colours[ppu.bgpColour[pixel]])
-		} else {
-			ppu.frame.SetRGBA(int(x), int(y), grey[ppu.bgpColour[pixel]])
+// setShade draws a DMG pixel and remembers its shade so that the SGB can colourise it
+func (ppu *PPU) setShade(x, y uint8, colours [4]color.RGBA, shade uint8) {
+	ppu.shades[y][x] = shade
+	ppu.frame.SetRGBA(int(x), int(y), colours[shade])
+}
+
+// outputPixelCGB draws a pixel using the CGB colour palettes and priorities. In CGB mode LCDC bit 0
+// no longer hides the background but instead takes away its priority over sprites.
+func (ppu *PPU) outputPixelCGB(x, y uint8, bg bgPixel, obj objPixel) {
+	if ppu.spritesEnabled && obj.Colour > 0 {
+		behindBackground := obj.Attributes&0x80 > 0 || bg.Attributes&0x80 > 0
+		if !ppu.bgEnabled || bg.Colour == 0 || !behindBackground {
+			ppu.frame.SetRGBA(int(x), int(y), cgbColour(&ppu.objPaletteRAM, obj.Attributes&0x07, obj.Colour))
+			return
 		}
 	}
-
+	ppu.frame.SetRGBA(int(x), int(y), cgbColour(&ppu.bgPaletteRAM, bg.Attributes&0x07, bg.Colour))
 }
 
-func (ppu *PPU) findWindowPixel(x, y uint8) uint8 {
-
-	tileX := x / 8
-	tileY := y / 8
-	tileOffsetX := x % 8
-	tileOffsetY := y % 8
+// cgbColour converts a colour from CGB palette memory from RGB555 to RGBA
+func cgbColour(paletteRAM *[0x40]byte, palette, pixel uint8) color.RGBA {
+	index := palette*8 + pixel*2
+	rgb555 := uint16(paletteRAM[index]) | uint16(paletteRAM[index+1])<<8
+	r := uint8(rgb555 & 0x1f)
+	g := uint8((rgb555 >> 5) & 0x1f)
+	b := uint8((rgb555 >> 10) & 0x1f)
+	return color.RGBA{r<<3 | r>>2, g<<3 | g>>2, b<<3 | b>>2, 0xff}
+}
 
-	var offsetAddr uint16
-	if ppu.highWindowTileMap {
-		offsetAddr = 0x9c00 - 0x8000
-	} else {
-		offsetAddr = 0x9800 - 0x8000
+// findSpritePixel returns the colour number of a sprite at the given pixel, along with the sprite
+// attributes. An 8x16 sprite uses a pair of tiles where the tile number ignores bit 0, and flipping
+// it vertically swaps the top and bottom tiles as well as flipping each one.
+func (ppu *PPU) findSpritePixel(spriteAddr uint16, x, y uint8) (uint8, uint8) {
+	spriteX := ppu.oam.PPURead(spriteAddr + 1)
+	spriteY := ppu.oam.PPURead(spriteAddr)
+	tileNumber := ppu.oam.PPURead(spriteAddr + 2)
+	attributes := ppu.oam.PPURead(spriteAddr + 3)
+	height := uint8(ppu.spriteHeight())
+	tileOffsetX := (x - spriteX) % 8
+	row := (y - spriteY) % height
+	flipY := attributes&0x40 > 0
+	flipX := attributes&0x20 > 0
+	if flipX {
+		tileOffsetX = 7 - tileOffsetX
 	}
-
-	var tileNumber int
-	tileAddr := 32*uint16(tileY) + uint16(tileX)
-	tileByte := ppu.videoRAM[offsetAddr+tileAddr]
-	if ppu.lowTileData {
-		tileNumber = int(tileByte)
-	} else {
-		tileNumber = 256 + int(int8(tileByte))
+	if flipY {
+		row = height - 1 - row
 	}
-
-	return ppu.readTilePixel(tileNumber, tileOffsetX, tileOffsetY)
-
-}
-
-func (ppu *PPU) findBackgroundPixel(x, y uint8) uint8 {
-
-	scx := ppu.ReadSCX()
-	scy := ppu.ReadSCY()
-	tileX := (x + scx) / 8
-	tileY := (y + scy) / 8
-	tileOffsetX := (x + scx) % 8
-	tileOffsetY := (y + scy) % 8
-
-	var offsetAddr uint16
-	if ppu.highBgTileMap {
-		offsetAddr = 0x9c00 - 0x8000
-	} else {
-		offsetAddr = 0x9800 - 0x8000
+	if height == 16 {
+		tileNumber = tileNumber&0xfe + row/8
 	}
-
-	var tileNumber int
-	tileAddr := 32*uint16(tileY) + uint16(tileX)
-	tileByte := ppu.videoRAM[offsetAddr+tileAddr]
-	if ppu.lowTileData {
-		tileNumber = int(tileByte)
-	} else {
-		tileNumber = 256 + int(int8(tileByte))
+	tileOffsetY := row % 8
+	// Only the CGB has a second bank of tiles
+	var bank uint8
+	if ppu.cgb && attributes&0x08 > 0 {
+		bank = 1
 	}
-
-	return ppu.readTilePixel(tileNumber, tileOffsetX, tileOffsetY)
-
+	return ppu.readTilePixel(bank, int(tileNumber), tileOffsetX, tileOffsetY), attributes
 }
 
-func (ppu *PPU) readTilePixel(tileNumber int, tileOffsetX, tileOffsetY uint8) uint8 {
-	startAddr := tileNumber * 16
-	a := ppu.videoRAM[startAddr+int(tileOffsetY*2)]
-	b := ppu.videoRAM[startAddr+int(tileOffsetY*2)+1]
-	aset := a&patterns[tileOffsetX] > 0
-	bset := b&patterns[tileOffsetX] > 0
-	switch {
-	case !aset && !bset:
-		return 0
-	case !aset && bset:
-		return 1
-	case aset && !bset:
-		return 2
-	case aset && bset:
-		return 3
-	default:
-		panic("error reading tile")
-	}
+// readTilePixel returns the colour number of a pixel in a tile. The first byte of each row holds the
+// low bit of each pixel's colour number and the second byte holds the high bit.
+func (ppu *PPU) readTilePixel(bank uint8, tileNumber int, tileOffsetX, tileOffsetY uint8) uint8 {
+	startAddr := int(bank)*0x2000 + tileNumber*16 + int(tileOffsetY)*2
+	low := ppu.videoRAM[startAddr] >> (7 - tileOffsetX) & 0x01
+	high := ppu.videoRAM[startAddr+1] >> (7 - tileOffsetX) & 0x01
+	return high<<1 | low
 }
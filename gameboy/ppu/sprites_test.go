@@ -0,0 +1,109 @@
+package ppu
+
+import (
+	"testing"
+
+	"github.com/scottyw/tetromino/gameboy/interrupts"
+	"github.com/scottyw/tetromino/gameboy/oam"
+)
+
+// newSpritePPU returns a DMG PPU with a blank background, identity palettes and tiles 1 to 3 filled
+// with colours 1 to 3
+func newSpritePPU() (*PPU, *oam.OAM) {
+	m := oam.New()
+	ppu := New(interrupts.New(), m, false, false)
+	ppu.WriteLCDC(0x00)
+	for tile := uint16(1); tile <= 3; tile++ {
+		for row := uint16(0); row < 8; row++ {
+			addr := 0x8000 + tile*16 + row*2
+			ppu.WriteVideoRAM(addr, uint8(tile&0x01)*0xff)
+			ppu.WriteVideoRAM(addr+1, uint8(tile>>1)*0xff)
+		}
+	}
+	ppu.WriteBGP(0xe4)
+	ppu.WriteOBP0(0xe4)
+	return ppu, m
+}
+
+func writeSprite(m *oam.OAM, sprite int, x, y, tile uint8) {
+	addr := 0xfe00 + uint16(sprite)*4
+	m.Write(addr, y)
+	m.Write(addr+1, x)
+	m.Write(addr+2, tile)
+	m.Write(addr+3, 0x00)
+}
+
+// renderFrame switches the LCD on and renders a complete frame after the first, which has no
+// sprites on its first line
+func renderFrame(ppu *PPU, lcdc uint8) {
+	ppu.WriteLCDC(lcdc)
+	for i := 0; i < 2*17556; i++ {
+		ppu.EndMachineCycle()
+	}
+}
+
+func assertShades(t *testing.T, ppu *PPU, y int, shades map[int]uint8) {
+	for x, shade := range shades {
+		if ppu.Shade(x, y) != shade {
+			t.Errorf("pixel (%d,%d): expected shade %d but found %d", x, y, shade, ppu.Shade(x, y))
+		}
+	}
+}
+
+func TestSpritesPerLine(t *testing.T) {
+	ppu, m := newSpritePPU()
+	for sprite := 0; sprite < 11; sprite++ {
+		writeSprite(m, sprite, uint8(8+sprite*10), 16, 3)
+	}
+	// Another sprite is only dropped on the lines it shares with the first 10
+	writeSprite(m, 11, 120, 20, 3)
+	renderFrame(ppu, 0x93)
+	assertShades(t, ppu, 0, map[int]uint8{0: 3, 90: 3, 97: 3, 100: 0, 107: 0, 112: 0})
+	assertShades(t, ppu, 4, map[int]uint8{90: 3, 100: 0, 112: 0})
+	assertShades(t, ppu, 8, map[int]uint8{0: 0, 100: 0, 112: 3})
+}
+
+func TestSpritesPerLineOffScreen(t *testing.T) {
+	ppu, m := newSpritePPU()
+	// Sprites off the left or right of the screen still count towards the limit
+	writeSprite(m, 0, 0, 16, 3)
+	writeSprite(m, 1, 168, 16, 3)
+	for sprite := 2; sprite < 11; sprite++ {
+		writeSprite(m, sprite, uint8(8+sprite*10), 16, 3)
+	}
+	renderFrame(ppu, 0x93)
+	assertShades(t, ppu, 0, map[int]uint8{20: 3, 90: 3, 100: 0})
+}
+
+func TestSpritePriorityDMG(t *testing.T) {
+	ppu, m := newSpritePPU()
+	// The sprite with the lower X coordinate wins even though it's later in OAM
+	writeSprite(m, 0, 20, 16, 1)
+	writeSprite(m, 1, 16, 16, 3)
+	// With equal X coordinates the sprite earlier in OAM wins
+	writeSprite(m, 2, 40, 16, 2)
+	writeSprite(m, 3, 40, 16, 3)
+	renderFrame(ppu, 0x93)
+	assertShades(t, ppu, 0, map[int]uint8{8: 3, 11: 3, 12: 3, 15: 3, 16: 1, 19: 1, 32: 2, 39: 2})
+}
+
+func TestLargeSprites(t *testing.T) {
+	ppu, m := newSpritePPU()
+	// Bit 0 of the tile number is ignored so both sprites use tile 2 on top and tile 3 below
+	writeSprite(m, 0, 8, 16, 3)
+	writeSprite(m, 1, 28, 16, 2)
+	m.Write(0xfe07, 0x40)
+	// Sprites partly above the top of the screen show their lower rows
+	writeSprite(m, 2, 48, 4, 2)
+	renderFrame(ppu, 0x97)
+	for y := 0; y < 8; y++ {
+		assertShades(t, ppu, y, map[int]uint8{0: 2, 20: 3})
+	}
+	for y := 8; y < 16; y++ {
+		assertShades(t, ppu, y, map[int]uint8{0: 3, 20: 2})
+	}
+	assertShades(t, ppu, 16, map[int]uint8{0: 0, 20: 0})
+	assertShades(t, ppu, 0, map[int]uint8{40: 3})
+	assertShades(t, ppu, 3, map[int]uint8{40: 3})
+	assertShades(t, ppu, 4, map[int]uint8{40: 0})
+}
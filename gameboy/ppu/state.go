@@ -0,0 +1,130 @@
+package ppu
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// state is the serialisable form of the PPU registers and internal state
+type state struct {
+	LCDC            uint8
+	STAT            uint8
+	Coincidence     bool
+	StatLine        bool
+	Mode            uint8
+	StatMode        uint8
+	LastMode        uint8
+	LineStart       bool
+	BGP             uint8
+	OBP0            uint8
+	OBP1            uint8
+	LY              uint8
+	LYC             uint8
+	SCX             uint8
+	SCY             uint8
+	WX              uint8
+	WY              uint8
+	VBK             uint8
+	BCPS            uint8
+	OCPS            uint8
+	BGPaletteRAM    [0x40]byte
+	OBJPaletteRAM   [0x40]byte
+	SpriteOverlaps  [40]bool
+	FIFO            pixelFIFO
+	WindowLine      uint8
+	WindowTriggered bool
+	Ticks           uint32
+	FirstLine       bool
+	FirstFrame      bool
+}
+
+// SaveState writes the PPU state including the pixel FIFOs and both banks of video RAM
+func (ppu *PPU) SaveState(w io.Writer) error {
+	err := binary.Write(w, binary.LittleEndian, &state{
+		LCDC:            ppu.ReadLCDC(),
+		STAT:            ppu.ReadSTAT(),
+		Coincidence:     ppu.coincidence,
+		StatLine:        ppu.statLine,
+		Mode:            ppu.mode,
+		StatMode:        ppu.statMode,
+		LastMode:        ppu.lastMode,
+		LineStart:       ppu.lineStart,
+		BGP:             ppu.ReadBGP(),
+		OBP0:            ppu.ReadOBP0(),
+		OBP1:            ppu.ReadOBP1(),
+		LY:              ppu.ly,
+		LYC:             ppu.lyc,
+		SCX:             ppu.scx,
+		SCY:             ppu.scy,
+		WX:              ppu.wx,
+		WY:              ppu.wy,
+		VBK:             ppu.vbk,
+		BCPS:            ppu.bcps,
+		OCPS:            ppu.ocps,
+		BGPaletteRAM:    ppu.bgPaletteRAM,
+		OBJPaletteRAM:   ppu.objPaletteRAM,
+		SpriteOverlaps:  ppu.spriteOverlaps,
+		FIFO:            ppu.fifo,
+		WindowLine:      ppu.windowLine,
+		WindowTriggered: ppu.windowTriggered,
+		Ticks:           uint32(ppu.ticks),
+		FirstLine:       ppu.firstLine,
+		FirstFrame:      ppu.firstFrame,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(ppu.videoRAM[:])
+	return err
+}
+
+// LoadState restores the PPU state including video RAM
+func (ppu *PPU) LoadState(r io.Reader) error {
+	var s state
+	err := binary.Read(r, binary.LittleEndian, &s)
+	if err != nil {
+		return err
+	}
+	_, err = io.ReadFull(r, ppu.videoRAM[:])
+	if err != nil {
+		return err
+	}
+	// Set the enabled flag directly to avoid the side-effects of switching the LCD on or off
+	ppu.enabled = s.LCDC&0x80 > 0
+	ppu.highWindowTileMap = s.LCDC&0x40 > 0
+	ppu.windowEnabled = s.LCDC&0x20 > 0
+	ppu.lowTileData = s.LCDC&0x10 > 0
+	ppu.highBgTileMap = s.LCDC&0x08 > 0
+	ppu.spritesLarge = s.LCDC&0x04 > 0
+	ppu.spritesEnabled = s.LCDC&0x02 > 0
+	ppu.bgEnabled = s.LCDC&0x01 > 0
+	ppu.writeSTATEnables(s.STAT)
+	ppu.coincidence = s.Coincidence
+	ppu.statLine = s.StatLine
+	ppu.mode = s.Mode
+	ppu.statMode = s.StatMode
+	ppu.lastMode = s.LastMode
+	ppu.lineStart = s.LineStart
+	ppu.WriteBGP(s.BGP)
+	ppu.WriteOBP0(s.OBP0)
+	ppu.WriteOBP1(s.OBP1)
+	ppu.ly = s.LY
+	ppu.lyc = s.LYC
+	ppu.scx = s.SCX
+	ppu.scy = s.SCY
+	ppu.wx = s.WX
+	ppu.wy = s.WY
+	ppu.vbk = s.VBK
+	ppu.bcps = s.BCPS
+	ppu.ocps = s.OCPS
+	ppu.bgPaletteRAM = s.BGPaletteRAM
+	ppu.objPaletteRAM = s.OBJPaletteRAM
+	ppu.spriteOverlaps = s.SpriteOverlaps
+	ppu.fifo = s.FIFO
+	ppu.windowLine = s.WindowLine
+	ppu.windowTriggered = s.WindowTriggered
+	ppu.ticks = int(s.Ticks)
+	ppu.firstLine = s.FirstLine
+	ppu.firstFrame = s.FirstFrame
+	return nil
+}
@@ -0,0 +1,87 @@
+package ppu
+
+import (
+	"testing"
+)
+
+// newWindowPPU returns a PPU with a blank background and a window map at 0x9c00 whose first row and
+// column of tiles are colour 3 while the rest are colour 1
+func newWindowPPU() *PPU {
+	ppu, _ := newSpritePPU()
+	for i := uint16(0); i < 0x400; i++ {
+		tile := uint8(1)
+		if i < 32 || i%32 == 0 {
+			tile = 3
+		}
+		ppu.WriteVideoRAM(0x9c00+i, tile)
+	}
+	return ppu
+}
+
+// startWindowFrame switches the LCD on with the window enabled and runs until the second frame
+// starts, since the first frame after switching on is shortened
+func startWindowFrame(ppu *PPU) {
+	ppu.WriteLCDC(0xf1)
+	for i := 0; i < 17556; i++ {
+		ppu.EndMachineCycle()
+	}
+}
+
+// runUntilLine runs the PPU until LY reaches the given line
+func runUntilLine(ppu *PPU, ly uint8) {
+	for ppu.ReadLY() != ly {
+		ppu.EndMachineCycle()
+	}
+}
+
+func TestWindowLineCounter(t *testing.T) {
+	ppu := newWindowPPU()
+	ppu.WriteWX(7)
+	startWindowFrame(ppu)
+	// Hiding the window on lines 4 to 11 pauses its line counter
+	runUntilLine(ppu, 4)
+	ppu.WriteLCDC(0xd1)
+	runUntilLine(ppu, 12)
+	ppu.WriteLCDC(0xf1)
+	runUntilLine(ppu, 144)
+	assertShades(t, ppu, 3, map[int]uint8{20: 3})
+	assertShades(t, ppu, 8, map[int]uint8{20: 0})
+	assertShades(t, ppu, 15, map[int]uint8{20: 3})
+	assertShades(t, ppu, 16, map[int]uint8{20: 1})
+}
+
+func TestWindowYLatch(t *testing.T) {
+	ppu := newWindowPPU()
+	ppu.WriteWX(7)
+	ppu.WriteWY(20)
+	startWindowFrame(ppu)
+	// Moving WY below the current line doesn't hide the window again until the next frame
+	runUntilLine(ppu, 30)
+	ppu.WriteWY(100)
+	runUntilLine(ppu, 144)
+	assertShades(t, ppu, 19, map[int]uint8{20: 0})
+	assertShades(t, ppu, 20, map[int]uint8{20: 3})
+	assertShades(t, ppu, 40, map[int]uint8{20: 1})
+	ppu.WriteWY(200)
+	runUntilLine(ppu, 0)
+	runUntilLine(ppu, 144)
+	assertShades(t, ppu, 40, map[int]uint8{20: 0})
+}
+
+func TestWindowX(t *testing.T) {
+	ppu := newWindowPPU()
+	// The window's leftmost 4 pixels are off the screen
+	ppu.WriteWX(3)
+	startWindowFrame(ppu)
+	runUntilLine(ppu, 144)
+	assertShades(t, ppu, 10, map[int]uint8{0: 3, 3: 3, 4: 1})
+	// Only the window's first pixel is on the screen
+	ppu.WriteWX(166)
+	runUntilLine(ppu, 0)
+	runUntilLine(ppu, 144)
+	assertShades(t, ppu, 10, map[int]uint8{158: 0, 159: 3})
+	ppu.WriteWX(167)
+	runUntilLine(ppu, 0)
+	runUntilLine(ppu, 144)
+	assertShades(t, ppu, 10, map[int]uint8{159: 0})
+}
@@ -0,0 +1,309 @@
+package printer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// Printer commands
+const (
+	initCommand   = uint8(0x01)
+	printCommand  = uint8(0x02)
+	dataCommand   = uint8(0x04)
+	statusCommand = uint8(0x0f)
+)
+
+// Status bits
+const (
+	checksumError   = uint8(0x01)
+	printing        = uint8(0x02)
+	imageDataFull   = uint8(0x04)
+	unprocessedData = uint8(0x08)
+)
+
+// Positions within a packet
+const (
+	magic1 = iota
+	magic2
+	command
+	compression
+	lengthLow
+	lengthHigh
+	payload
+	checksumLow
+	checksumHigh
+	keepAlive
+	statusReport
+)
+
+const (
+	// Paper is 20 tiles wide and the printer holds at most 18 rows of tiles
+	tilesPerRow = 20
+	bufferSize  = tilesPerRow * 18 * 16
+
+	// Each unit of margin feeds a tile row of blank paper
+	marginRows = 8
+
+	// Printing is reported as in progress for this many status requests
+	printDuration = 4
+)
+
+var shades = []color.Gray{{0xff}, {0xaa}, {0x55}, {0x00}}
+
+// Printer emulates a Game Boy Printer plugged into the serial port
+type Printer struct {
+	directory string
+	prints    int
+
+	// Packet being received
+	position    int
+	command     uint8
+	compression uint8
+	length      uint16
+	payload     []byte
+	checksum    uint16
+	sum         uint16
+
+	// Printer state
+	status uint8
+	buffer []byte
+	busy   int
+
+	// Bands printed so far in a print job that hasn't ended yet
+	job *image.Gray
+}
+
+// New returns a printer that writes each print job as a PNG in the directory
+func New(directory string) *Printer {
+	return &Printer{
+		directory: directory,
+	}
+}
+
+// Exchange receives a byte of a packet from the Gameboy and returns the printer's response
+func (p *Printer) Exchange(in uint8) uint8 {
+	switch p.position {
+	case magic1:
+		if in == 0x88 {
+			p.position = magic2
+		}
+	case magic2:
+		if in == 0x33 {
+			p.position = command
+		} else {
+			p.position = magic1
+		}
+	case command:
+		p.command = in
+		p.sum = uint16(in)
+		p.position = compression
+	case compression:
+		p.compression = in
+		p.sum += uint16(in)
+		p.position = lengthLow
+	case lengthLow:
+		p.length = uint16(in)
+		p.sum += uint16(in)
+		p.position = lengthHigh
+	case lengthHigh:
+		p.length |= uint16(in) << 8
+		p.sum += uint16(in)
+		p.payload = p.payload[:0]
+		if p.length > 0 {
+			p.position = payload
+		} else {
+			p.position = checksumLow
+		}
+	case payload:
+		p.payload = append(p.payload, in)
+		p.sum += uint16(in)
+		if len(p.payload) == int(p.length) {
+			p.position = checksumLow
+		}
+	case checksumLow:
+		p.checksum = uint16(in)
+		p.position = checksumHigh
+	case checksumHigh:
+		p.checksum |= uint16(in) << 8
+		p.position = keepAlive
+		p.handlePacket()
+	case keepAlive:
+		p.position = statusReport
+		return 0x81
+	case statusReport:
+		p.position = magic1
+		return p.status
+	}
+	return 0x00
+}
+
+func (p *Printer) handlePacket() {
+	if p.checksum != p.sum {
+		p.status |= checksumError
+		return
+	}
+	p.status &^= checksumError
+	switch p.command {
+	case initCommand:
+		p.buffer = p.buffer[:0]
+		p.status = 0
+		p.busy = 0
+	case dataCommand:
+		if p.compression != 0 {
+			p.buffer = append(p.buffer, decompress(p.payload)...)
+		} else {
+			p.buffer = append(p.buffer, p.payload...)
+		}
+		if len(p.buffer) > bufferSize {
+			p.buffer = p.buffer[:bufferSize]
+		}
+		if len(p.buffer) > 0 {
+			p.status |= unprocessedData
+		}
+		if len(p.buffer) == bufferSize {
+			p.status |= imageDataFull
+		}
+	case printCommand:
+		if len(p.payload) < 4 {
+			return
+		}
+		p.printBuffer(p.payload[1], p.payload[2])
+		p.buffer = p.buffer[:0]
+		p.status &^= unprocessedData | imageDataFull
+		p.status |= printing
+		p.busy = printDuration
+	case statusCommand:
+		if p.busy > 0 {
+			p.busy--
+			if p.busy == 0 {
+				p.status &^= printing
+			}
+		}
+	}
+}
+
+// decompress expands run-length encoded image data. A control byte with bit 7 set is followed by
+// a single byte repeated (control & 0x7f) + 2 times, otherwise by (control + 1) literal bytes.
+func decompress(compressed []byte) []byte {
+	var decompressed []byte
+	for i := 0; i < len(compressed); {
+		control := compressed[i]
+		i++
+		if control&0x80 != 0 {
+			if i >= len(compressed) {
+				break
+			}
+			for n := 0; n < int(control&0x7f)+2; n++ {
+				decompressed = append(decompressed, compressed[i])
+			}
+			i++
+		} else {
+			for n := 0; n <= int(control) && i < len(compressed); n++ {
+				decompressed = append(decompressed, compressed[i])
+				i++
+			}
+		}
+	}
+	return decompressed
+}
+
+// render decodes the 2bpp tiles in the buffer into an image with the requested palette and margins
+func (p *Printer) render(margins, palette uint8) *image.Gray {
+	// A zero palette is treated as the usual mapping
+	if palette == 0 {
+		palette = 0xe4
+	}
+	before := int(margins>>4) * marginRows
+	after := int(margins&0x0f) * marginRows
+	tileRows := len(p.buffer) / (tilesPerRow * 16)
+	img := image.NewGray(image.Rect(0, 0, tilesPerRow*8, before+tileRows*8+after))
+	for i := range img.Pix {
+		img.Pix[i] = shades[0].Y
+	}
+	for tile := 0; tile < tileRows*tilesPerRow; tile++ {
+		tileX := (tile % tilesPerRow) * 8
+		tileY := before + (tile/tilesPerRow)*8
+		for row := 0; row < 8; row++ {
+			low := p.buffer[tile*16+row*2]
+			high := p.buffer[tile*16+row*2+1]
+			for col := 0; col < 8; col++ {
+				// Tile bits are counted left-to-right so bit 0 of the tile is bit 7 of the byte
+				bit := uint(7 - col)
+				index := (low>>bit)&1 | ((high>>bit)&1)<<1
+				shade := (palette >> (index * 2)) & 0x03
+				img.SetGray(tileX+col, tileY+row, shades[shade])
+			}
+		}
+	}
+	return img
+}
+
+// appendBand adds a band below the bands already printed in a job
+func appendBand(job, band *image.Gray) *image.Gray {
+	if job == nil {
+		return band
+	}
+	img := image.NewGray(image.Rect(0, 0, tilesPerRow*8, job.Bounds().Dy()+band.Bounds().Dy()))
+	copy(img.Pix, job.Pix)
+	copy(img.Pix[len(job.Pix):], band.Pix)
+	return img
+}
+
+// printBuffer adds the buffer to the print job as a band. Games print larger pictures as several
+// bands with no margin after all but the last, so the job is only written to the next unused PNG
+// filename in the directory once a band has a margin after it.
+func (p *Printer) printBuffer(margins, palette uint8) {
+	if len(p.buffer) == 0 && p.job == nil {
+		return
+	}
+	p.job = appendBand(p.job, p.render(margins, palette))
+	if margins&0x0f == 0 {
+		return
+	}
+	p.Flush()
+}
+
+// Flush writes any bands of a print job that hasn't ended yet. It's called when the emulator quits
+// so that a picture whose last band had no margin after it isn't lost.
+func (p *Printer) Flush() {
+	if p.job == nil {
+		return
+	}
+	img := p.job
+	p.job = nil
+	filename, err := p.writePNG(img)
+	if err != nil {
+		fmt.Printf("Failed to save print (%v)\n", err)
+		return
+	}
+	fmt.Printf("Printed to %s\n", filename)
+}
+
+// writePNG writes an image to the next unused PNG filename in the directory and returns the filename.
+// The print counter only moves on once the file has been created.
+func (p *Printer) writePNG(img image.Image) (string, error) {
+	prints := p.prints
+	var filename string
+	for {
+		prints++
+		filename = filepath.Join(p.directory, fmt.Sprintf("print-%04d.png", prints))
+		if _, err := os.Stat(filename); os.IsNotExist(err) {
+			break
+		}
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	p.prints = prints
+	err = png.Encode(f, img)
+	if err != nil {
+		return "", err
+	}
+	return filename, nil
+}
@@ -0,0 +1,252 @@
+package printer
+
+import (
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// send transmits a packet and returns the keep-alive and status responses
+func send(p *Printer, command, compression uint8, payload []byte) (uint8, uint8) {
+	length := uint16(len(payload))
+	packet := []byte{0x88, 0x33, command, compression, uint8(length), uint8(length >> 8)}
+	packet = append(packet, payload...)
+	sum := uint16(command) + uint16(compression) + uint16(uint8(length)) + uint16(uint8(length>>8))
+	for _, b := range payload {
+		sum += uint16(b)
+	}
+	packet = append(packet, uint8(sum), uint8(sum>>8))
+	for _, b := range packet {
+		if p.Exchange(b) != 0x00 {
+			return 0xff, 0xff
+		}
+	}
+	return p.Exchange(0x00), p.Exchange(0x00)
+}
+
+func TestPrint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "printer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p := New(dir)
+
+	alive, status := send(p, initCommand, 0, nil)
+	if alive != 0x81 || status != 0x00 {
+		t.Fatalf("Wrong init response: 0x%02x 0x%02x", alive, status)
+	}
+
+	// Two rows of tiles where every pixel uses colour 1, except the first tile which uses colour 3
+	band := make([]byte, 2*tilesPerRow*16)
+	for i := 0; i < len(band); i += 2 {
+		band[i] = 0xff
+	}
+	for i := 1; i < 16; i += 2 {
+		band[i] = 0xff
+	}
+	_, status = send(p, dataCommand, 0, band)
+	if status != unprocessedData {
+		t.Errorf("Wrong status after data: 0x%02x", status)
+	}
+
+	// A band of colour 3 using run-length encoding to repeat 0xff 640 times
+	compressed := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfa, 0xff}
+	_, status = send(p, dataCommand, 1, compressed)
+	if status != unprocessedData {
+		t.Errorf("Wrong status after compressed data: 0x%02x", status)
+	}
+	send(p, dataCommand, 0, nil)
+
+	// Print with one row of margin before and two after
+	_, status = send(p, printCommand, 0, []byte{0x01, 0x12, 0xe4, 0x40})
+	if status != printing {
+		t.Errorf("Wrong status after print: 0x%02x", status)
+	}
+	for i := 0; i < printDuration; i++ {
+		_, status = send(p, statusCommand, 0, nil)
+	}
+	if status != 0x00 {
+		t.Errorf("Wrong status after printing: 0x%02x", status)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "print-0001.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 160 || img.Bounds().Dy() != 8+32+16 {
+		t.Fatalf("Wrong image size: %v", img.Bounds())
+	}
+	expected := map[[2]int]uint32{
+		{0, 0}:      0xffff, // Margin
+		{0, 8}:      0x0000, // Colour 3 in the first tile
+		{8, 8}:      0xaaaa, // Colour 1
+		{0, 24}:     0x0000, // Colour 3 in the compressed band
+		{159, 39}:   0x0000,
+		{0, 40 + 8}: 0xffff, // Margin
+	}
+	for xy, grey := range expected {
+		r, _, _, _ := img.At(xy[0], xy[1]).RGBA()
+		if r != grey {
+			t.Errorf("Wrong pixel at %v: 0x%04x", xy, r)
+		}
+	}
+}
+
+func TestChecksumError(t *testing.T) {
+	p := New("")
+	for _, b := range []byte{0x88, 0x33, initCommand, 0x00, 0x00, 0x00, 0x02, 0x00} {
+		p.Exchange(b)
+	}
+	if p.Exchange(0x00) != 0x81 {
+		t.Error("Wrong keep-alive response")
+	}
+	if p.Exchange(0x00) != checksumError {
+		t.Error("Expected checksum error")
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	actual := decompress([]byte{0x02, 0x01, 0x02, 0x03, 0x81, 0x04})
+	expected := []byte{0x01, 0x02, 0x03, 0x04, 0x04, 0x04}
+	if string(actual) != string(expected) {
+		t.Errorf("Wrong decompressed data: %v", actual)
+	}
+}
+
+// printBands prints a picture as bands of two tile rows, the first in colour 3 and the rest in colour
+// 1, using the given margins for each band
+func printBands(p *Printer, margins ...uint8) {
+	for i, m := range margins {
+		band := make([]byte, 2*tilesPerRow*16)
+		for j := 0; j < len(band); j += 2 {
+			band[j] = 0xff
+			if i == 0 {
+				band[j+1] = 0xff
+			}
+		}
+		send(p, initCommand, 0, nil)
+		send(p, dataCommand, 0, band)
+		send(p, dataCommand, 0, nil)
+		send(p, printCommand, 0, []byte{0x01, m, 0xe4, 0x40})
+		for j := 0; j < printDuration; j++ {
+			send(p, statusCommand, 0, nil)
+		}
+	}
+}
+
+// countPrints returns the number of files in the print directory
+func countPrints(t *testing.T, dir string) int {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(files)
+}
+
+func TestPrintBands(t *testing.T) {
+	dir, err := ioutil.TempDir("", "printer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p := New(dir)
+
+	// Two bands with a margin only after the last
+	printBands(p, 0x10, 0x02)
+	if n := countPrints(t, dir); n != 1 {
+		t.Fatalf("expected one print but found %d", n)
+	}
+	f, err := os.Open(filepath.Join(dir, "print-0001.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 160 || img.Bounds().Dy() != 8+16+16+16 {
+		t.Fatalf("Wrong image size: %v", img.Bounds())
+	}
+	expected := map[[2]int]uint32{
+		{0, 0}:    0xffff, // Margin before the first band
+		{0, 8}:    0x0000, // Colour 3 in the first band
+		{159, 23}: 0x0000,
+		{0, 24}:   0xaaaa, // Colour 1 in the second band
+		{159, 39}: 0xaaaa,
+		{0, 40}:   0xffff, // Margin after the second band
+	}
+	for xy, grey := range expected {
+		r, _, _, _ := img.At(xy[0], xy[1]).RGBA()
+		if r != grey {
+			t.Errorf("Wrong pixel at %v: 0x%04x", xy, r)
+		}
+	}
+}
+
+func TestFlushPrintJob(t *testing.T) {
+	dir, err := ioutil.TempDir("", "printer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p := New(dir)
+
+	// Two bands with no margin after the last aren't written until the job is flushed
+	printBands(p, 0x10, 0x00)
+	if n := countPrints(t, dir); n != 0 {
+		t.Fatalf("expected no prints before flushing but found %d", n)
+	}
+	p.Flush()
+	p.Flush()
+	if n := countPrints(t, dir); n != 1 {
+		t.Fatalf("expected one print after flushing but found %d", n)
+	}
+	f, err := os.Open(filepath.Join(dir, "print-0001.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 160 || img.Bounds().Dy() != 8+16+16 {
+		t.Fatalf("Wrong image size: %v", img.Bounds())
+	}
+}
+
+func TestWritePNGFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "printer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The print counter doesn't move on when the file can't be created
+	p := New(filepath.Join(dir, "missing"))
+	img := image.NewGray(image.Rect(0, 0, tilesPerRow*8, 8))
+	if _, err := p.writePNG(img); err == nil {
+		t.Fatal("expected an error writing to a missing directory")
+	}
+	err = os.Mkdir(p.directory, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename, err := p.writePNG(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(filename) != "print-0001.png" {
+		t.Errorf("Wrong filename: %s", filename)
+	}
+}
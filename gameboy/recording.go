@@ -0,0 +1,67 @@
+package gameboy
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+
+	"github.com/scottyw/tetromino/gameboy/video"
+)
+
+// startVideo begins recording frames to an animated GIF or APNG file
+func (gb *Gameboy) startVideo(filename string) error {
+	recorder, err := video.New(filename, lcdBounds.Dx(), lcdBounds.Dy(), gb.config.VideoScale)
+	if err != nil {
+		return err
+	}
+	gb.video = recorder
+	gb.videoFilename = filename
+	return nil
+}
+
+// stopVideo finishes any video recording in progress
+func (gb *Gameboy) stopVideo() {
+	if gb.video == nil {
+		return
+	}
+	err := gb.video.Close()
+	if err != nil {
+		fmt.Printf("Failed to record the video at \"%s\" (%v)\n", gb.videoFilename, err)
+	} else {
+		fmt.Printf("Video saved to %s\n", gb.videoFilename)
+	}
+	gb.video = nil
+}
+
+// toggleVideo starts or stops recording. Recordings started from the keyboard are named after the
+// game and the time, in the same directory and format as the configured recording.
+func (gb *Gameboy) toggleVideo() {
+	if gb.video != nil {
+		gb.stopVideo()
+		return
+	}
+	ext := ".gif"
+	if gb.config.RecordVideo != "" {
+		ext = filepath.Ext(gb.config.RecordVideo)
+	}
+	filename := gb.timestampedFilename(ext)
+	err := gb.startVideo(filename)
+	if err != nil {
+		fmt.Printf("Failed to record video (%v)\n", err)
+		return
+	}
+	fmt.Printf("Recording video to %s\n", filename)
+}
+
+// recordVideoFrame adds the LCD frame to the recording
+func (gb *Gameboy) recordVideoFrame() {
+	if gb.video == nil {
+		return
+	}
+	err := gb.video.AddFrame(gb.ppu.Frame().SubImage(lcdBounds).(*image.RGBA))
+	if err != nil {
+		fmt.Printf("Failed to record the video at \"%s\" (%v)\n", gb.videoFilename, err)
+		gb.video.Close()
+		gb.video = nil
+	}
+}
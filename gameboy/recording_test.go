@@ -0,0 +1,41 @@
+package gameboy
+
+import (
+	"context"
+	"image"
+	"image/gif"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordVideo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "video")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "cpu_instrs.gif")
+	gb := New(Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		RecordVideo:        filename,
+		VideoScale:         2,
+		FrameLimit:         30,
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	})
+	gb.Run(context.Background())
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	g, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Image) != 30 || g.Image[0].Bounds() != image.Rect(0, 0, 320, 288) {
+		t.Errorf("expected 30 frames of 320x288 but found %d of %v", len(g.Image), g.Image[0].Bounds())
+	}
+}
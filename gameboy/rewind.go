@@ -0,0 +1,119 @@
+package gameboy
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io/ioutil"
+)
+
+// rewindBuffer is a ring buffer of per-frame snapshots used to play the game
+// backwards. Only the latest snapshot is held in full. Every earlier frame is
+// stored as the compressed XOR of itself and the frame that followed it, which
+// is mostly zeros from one frame to the next and so compresses very well.
+// Walking backwards through the deltas recovers each earlier snapshot in turn.
+// The oldest deltas are discarded once the memory budget is exceeded.
+type rewindBuffer struct {
+	budget     int
+	used       int
+	deltas     [][]byte // Oldest first, starting at index head
+	head       int
+	latest     []byte
+	scratch    []byte
+	compressed bytes.Buffer
+	compressor *flate.Writer
+}
+
+func newRewindBuffer(budget int) *rewindBuffer {
+	compressor, err := flate.NewWriter(nil, flate.BestSpeed)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create rewind buffer: %v", err))
+	}
+	return &rewindBuffer{
+		budget:     budget,
+		compressor: compressor,
+	}
+}
+
+// push records the snapshot for the frame that just finished
+func (rb *rewindBuffer) push(snapshot []byte) {
+	if len(rb.latest) != len(snapshot) {
+		// First frame (or the snapshot layout changed) so there is nothing to diff against
+		rb.deltas = nil
+		rb.head = 0
+		rb.used = 0
+		rb.latest = append([]byte(nil), snapshot...)
+		rb.scratch = make([]byte, len(snapshot))
+		return
+	}
+	for i := range snapshot {
+		rb.scratch[i] = rb.latest[i] ^ snapshot[i]
+	}
+	rb.compressed.Reset()
+	rb.compressor.Reset(&rb.compressed)
+	rb.compressor.Write(rb.scratch)
+	rb.compressor.Close()
+	delta := append([]byte(nil), rb.compressed.Bytes()...)
+	rb.deltas = append(rb.deltas, delta)
+	rb.used += len(delta)
+	copy(rb.latest, snapshot)
+
+	// Drop the oldest frames until we're back within budget
+	for rb.used > rb.budget && rb.head < len(rb.deltas) {
+		rb.used -= len(rb.deltas[rb.head])
+		rb.deltas[rb.head] = nil
+		rb.head++
+	}
+	if rb.head > len(rb.deltas)/2 {
+		rb.deltas = append(rb.deltas[:0], rb.deltas[rb.head:]...)
+		rb.head = 0
+	}
+}
+
+// pop steps back one frame and returns its snapshot, or returns nil if there
+// is no more history
+func (rb *rewindBuffer) pop() []byte {
+	if rb.head == len(rb.deltas) {
+		return nil
+	}
+	last := len(rb.deltas) - 1
+	delta, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(rb.deltas[last])))
+	if err != nil || len(delta) != len(rb.latest) {
+		panic(fmt.Sprintf("Rewind buffer is corrupt: %v", err))
+	}
+	rb.used -= len(rb.deltas[last])
+	rb.deltas[last] = nil
+	rb.deltas = rb.deltas[:last]
+	for i := range delta {
+		rb.latest[i] ^= delta[i]
+	}
+	return rb.latest
+}
+
+// recordRewind adds the machine state and frame at the end of this frame to the rewind buffer
+func (gb *Gameboy) recordRewind() {
+	gb.rewindSnapshot.Reset()
+	err := gb.SaveState(&gb.rewindSnapshot)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to record rewind snapshot: %v", err))
+	}
+	gb.rewindSnapshot.Write(gb.screen().Pix)
+	gb.rewind.push(gb.rewindSnapshot.Bytes())
+}
+
+// rewindFrame steps the machine back by one frame, restoring the frame image too
+func (gb *Gameboy) rewindFrame() {
+	// Keep the speakers fed with silence for a frame so rewinding plays back at normal speed
+	gb.audio.Silence(17556)
+	snapshot := gb.rewind.pop()
+	if snapshot == nil {
+		return
+	}
+	pix := gb.screen().Pix
+	stateLen := len(snapshot) - len(pix)
+	err := gb.LoadState(bytes.NewReader(snapshot[:stateLen]))
+	if err != nil {
+		panic(fmt.Sprintf("Failed to restore rewind snapshot: %v", err))
+	}
+	copy(pix, snapshot[stateLen:])
+}
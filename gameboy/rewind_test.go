@@ -0,0 +1,64 @@
+package gameboy
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/scottyw/tetromino/gameboy/controller"
+)
+
+func TestRewind(t *testing.T) {
+	gameboy := New(Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+		RewindBudget:       16 * 1024 * 1024,
+	})
+	runFrames(gameboy, 30)
+	expected := &bytes.Buffer{}
+	err := gameboy.SaveState(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedFrame := append([]byte(nil), gameboy.ppu.Frame().Pix...)
+	runFrames(gameboy, 20)
+
+	// Play backwards for 20 frames to get back to where we were
+	gameboy.onAction(controller.StartRewind, 0)
+	runFrames(gameboy, 20)
+	gameboy.onAction(controller.StopRewind, 0)
+	actual := &bytes.Buffer{}
+	err = gameboy.SaveState(actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expected.Bytes(), actual.Bytes()) {
+		t.Error("machine state differs after rewinding")
+	}
+	if !bytes.Equal(expectedFrame, gameboy.ppu.Frame().Pix) {
+		t.Error("frame differs after rewinding")
+	}
+}
+
+func TestRewindBudget(t *testing.T) {
+	gameboy := New(Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+		RewindBudget:       64 * 1024,
+	})
+	for i := 0; i < 200; i++ {
+		gameboy.runFrame(context.Background())
+		if gameboy.rewind.used > gameboy.rewind.budget {
+			t.Fatalf("rewind buffer exceeds budget: %d > %d", gameboy.rewind.used, gameboy.rewind.budget)
+		}
+	}
+
+	// Rewinding past the oldest history leaves the machine at the oldest frame
+	gameboy.onAction(controller.StartRewind, 0)
+	runFrames(gameboy, 200)
+	if gameboy.rewind.pop() != nil {
+		t.Error("expected rewind history to be exhausted")
+	}
+}
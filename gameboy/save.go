@@ -0,0 +1,49 @@
+package gameboy
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// Battery-backed RAM is flushed to disk every 600 frames i.e. roughly every 10 seconds
+const saveInterval = 600
+
+// loadSaveRAM restores battery-backed cart RAM from the save file if one exists
+func (gb *Gameboy) loadSaveRAM() {
+	if gb.config.SaveFilename == "" || !gb.mapper.HasBattery() {
+		return
+	}
+	data, err := ioutil.ReadFile(gb.config.SaveFilename)
+	if err == nil {
+		gb.mapper.LoadRAM(data)
+	} else if !os.IsNotExist(err) {
+		fmt.Printf("Failed to read the save file at \"%s\" (%v)\n", gb.config.SaveFilename, err)
+	}
+	gb.savedRAM = append([]byte(nil), gb.mapper.DumpRAM()...)
+}
+
+// flushSaveRAM writes battery-backed cart RAM to the save file if it has changed since the last flush
+func (gb *Gameboy) flushSaveRAM() {
+	if gb.config.SaveFilename == "" || !gb.mapper.HasBattery() {
+		return
+	}
+	ram := gb.mapper.DumpRAM()
+	if bytes.Equal(ram, gb.savedRAM) {
+		return
+	}
+	// Write to a temporary file first so that a crash mid-write can't corrupt an existing save
+	tmp := gb.config.SaveFilename + ".tmp"
+	err := ioutil.WriteFile(tmp, ram, 0644)
+	if err != nil {
+		fmt.Printf("Failed to write the save file at \"%s\" (%v)\n", gb.config.SaveFilename, err)
+		return
+	}
+	err = os.Rename(tmp, gb.config.SaveFilename)
+	if err != nil {
+		fmt.Printf("Failed to write the save file at \"%s\" (%v)\n", gb.config.SaveFilename, err)
+		return
+	}
+	gb.savedRAM = append([]byte(nil), ram...)
+}
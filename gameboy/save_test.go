@@ -0,0 +1,86 @@
+package gameboy
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveRAM(t *testing.T) {
+	// The sound test ROM is an MBC1 cart with 8KB of battery-backed RAM
+	batteryROM := "testdata/blargg/dmg_sound/dmg_sound.gb"
+	for _, test := range []struct {
+		name     string
+		rom      string
+		existing []byte // Contents of the save file before starting, with no file when nil
+		loaded   []byte // Cart RAM expected at A000 after loading
+		saved    bool   // Whether the save file should be written
+	}{
+		{"no battery", "testdata/blargg/cpu_instrs/cpu_instrs.gb", nil, nil, false},
+		{"no save file", batteryROM, nil, []byte{0xff, 0xff}, true},
+		{"short save file", batteryROM, []byte{0x12, 0x34}, []byte{0x12, 0x34, 0xff}, true},
+		{"oversized save file", batteryROM, bytes.Repeat([]byte{0x56}, 0x3000), []byte{0x56, 0x56}, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "save")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			filename := filepath.Join(dir, "game.sav")
+			if test.existing != nil {
+				err = ioutil.WriteFile(filename, test.existing, 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			gb := New(Config{
+				RomFilename:        test.rom,
+				SaveFilename:       filename,
+				DisableVideoOutput: true,
+				DisableAudioOutput: true,
+			})
+
+			// Enable cart RAM then check what was loaded before changing it
+			gb.mapper.Write(0x0000, 0x0a)
+			for i, expected := range test.loaded {
+				if actual := gb.mapper.Read(0xa000 + uint16(i)); actual != expected {
+					t.Errorf("expected 0x%02x at 0x%04x but read 0x%02x", expected, 0xa000+i, actual)
+				}
+			}
+			gb.mapper.Write(0xa000, 0x78)
+			gb.flushSaveRAM()
+
+			// The temporary file is always renamed over the save file
+			files, err := ioutil.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range files {
+				if strings.HasSuffix(f.Name(), ".tmp") {
+					t.Errorf("temporary file %s left behind", f.Name())
+				}
+			}
+
+			data, err := ioutil.ReadFile(filename)
+			if !test.saved {
+				if !os.IsNotExist(err) {
+					t.Errorf("expected no save file but found one (%v)", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) != 0x2000 {
+				t.Fatalf("expected an 8KB save file but it's %d bytes", len(data))
+			}
+			if data[0] != 0x78 || data[1] != test.loaded[1] {
+				t.Errorf("wrong save file contents: 0x%02x 0x%02x", data[0], data[1])
+			}
+		})
+	}
+}
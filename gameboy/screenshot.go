@@ -0,0 +1,56 @@
+package gameboy
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// lcdBounds is the area of the PPU frame showing the LCD, which is all of it unless the debug frame
+// shows the rest of the background map too
+var lcdBounds = image.Rect(0, 0, 160, 144)
+
+// takeScreenshot writes the screen to a timestamped PNG file in the screenshot directory and returns
+// the filename. Unless raw screenshots are configured the image matches what the display shows,
+// which includes the SGB border or the 256x256 debug frame.
+func (gb *Gameboy) takeScreenshot() (string, error) {
+	var img image.Image = gb.screen()
+	if gb.config.ScreenshotRaw {
+		img = gb.ppu.Frame().SubImage(lcdBounds)
+	}
+	if gb.config.ScreenshotScale > 1 {
+		img = scaleImage(img, gb.config.ScreenshotScale)
+	}
+	filename := gb.timestampedFilename(".png")
+	f, err := os.Create(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return filename, png.Encode(f, img)
+}
+
+// timestampedFilename returns a filename in the screenshot directory named after the game and the
+// current time
+func (gb *Gameboy) timestampedFilename(ext string) string {
+	rom := filepath.Base(gb.config.RomFilename)
+	name := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(rom, filepath.Ext(rom)), time.Now().Format("20060102-150405.000"), ext)
+	return filepath.Join(gb.config.ScreenshotDir, name)
+}
+
+// scaleImage returns a copy of the image enlarged by a whole number factor with each pixel becoming
+// a square block so that the pixels stay sharp
+func scaleImage(img image.Image, factor int) *image.RGBA {
+	bounds := img.Bounds()
+	scaled := image.NewRGBA(image.Rect(0, 0, bounds.Dx()*factor, bounds.Dy()*factor))
+	for y := 0; y < scaled.Bounds().Dy(); y++ {
+		for x := 0; x < scaled.Bounds().Dx(); x++ {
+			scaled.Set(x, y, img.At(bounds.Min.X+x/factor, bounds.Min.Y+y/factor))
+		}
+	}
+	return scaled
+}
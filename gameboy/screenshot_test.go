@@ -0,0 +1,58 @@
+package gameboy
+
+import (
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScreenshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "screenshots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, test := range []struct {
+		raw           bool
+		scale         int
+		width, height int
+	}{
+		{false, 0, 256, 256},
+		{true, 0, 160, 144},
+		{true, 3, 480, 432},
+	} {
+		gb := New(Config{
+			RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+			ScreenshotDir:      dir,
+			ScreenshotScale:    test.scale,
+			ScreenshotRaw:      test.raw,
+			DisableVideoOutput: true,
+			DisableAudioOutput: true,
+			DebugLCD:           true,
+		})
+		filename, err := gb.takeScreenshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Dir(filename) != dir || !strings.HasPrefix(filepath.Base(filename), "cpu_instrs-") {
+			t.Errorf("unexpected screenshot filename %s", filename)
+		}
+		f, err := os.Open(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		bounds := img.Bounds()
+		if bounds.Min.X != 0 || bounds.Min.Y != 0 || bounds.Dx() != test.width || bounds.Dy() != test.height {
+			t.Errorf("raw %v, scale %d: expected a %dx%d screenshot but found %v", test.raw, test.scale, test.width, test.height, bounds)
+		}
+		os.Remove(filename)
+	}
+}
@@ -5,10 +5,34 @@ import (
 	"io"
 )
 
+// Peer is whatever is plugged into the other end of the link cable
+type Peer interface {
+	// Exchange shifts a byte out to the peer and returns the byte shifted back in
+	Exchange(out uint8) uint8
+}
+
+// RemotePeer is a peer whose reply arrives some time later, such as another Gameboy over a network.
+// The transfer stays in progress until the reply is passed to Reply or the wait times out.
+type RemotePeer interface {
+	// Send shifts a byte out to the peer
+	Send(out uint8)
+}
+
+// replyTimeout is how many machine cycles, about a second, a transfer waits for a remote peer to
+// reply before carrying on as though nothing were connected
+const replyTimeout = 1 << 20
+
 // Serial captures the current state of serial bus
 type Serial struct {
-	sc     byte
-	writer io.Writer
+	sb        byte
+	sc        byte
+	clock     bool
+	bits      int
+	interrupt bool
+	peer      Peer
+	remote    RemotePeer
+	waiting   int // Machine cycles spent waiting for a remote peer to reply (0 when not waiting)
+	writer    io.Writer
 }
 
 // New Serial
@@ -18,32 +42,124 @@ func New(writer io.Writer) *Serial {
 	}
 }
 
-// WriteSB handles writes to register SB
-func (s *Serial) WriteSB(value uint8) {
-	// fmt.Printf("> SB - 0x%02x\n", value)
-	if s.writer == nil {
+// EndMachineCycle shifts the serial transfer in progress and returns true when it completes. The
+// internal clock runs at 8192Hz and is derived from the same counter as DIV so transfers are aligned
+// to it, shifting one bit on each falling edge of bit 8.
+func (s *Serial) EndMachineCycle(counter uint16) bool {
+	clock := counter&0x100 > 0
+	edge := s.clock && !clock
+	s.clock = clock
+
+	// A transfer clocked by the peer may have completed
+	if s.interrupt {
+		s.interrupt = false
+		return true
+	}
+
+	// A transfer sent to a remote peer completes when the reply arrives or the wait times out
+	if s.waiting > 0 {
+		s.waiting++
+		if s.waiting <= replyTimeout {
+			return false
+		}
+		s.waiting = 0
+		s.sb = 0xff
+		s.sc &^= 0x80
+		return true
+	}
+
+	// With an external clock the transfer waits for the peer to clock it
+	if s.sc&0x81 != 0x81 || !edge {
+		return false
+	}
+	s.bits++
+	if s.bits < 8 {
+		// When nothing is connected every bit shifted in is high
+		if s.peer == nil && s.remote == nil {
+			s.sb = s.sb<<1 | 0x01
+		}
+		return false
+	}
+	s.bits = 0
+	if s.remote != nil {
+		s.remote.Send(s.sb)
+		s.waiting = 1
+		return false
+	}
+	s.sc &^= 0x80
+	if s.peer != nil {
+		s.sb = s.peer.Exchange(s.sb)
+	} else {
+		s.sb = s.sb<<1 | 0x01
+	}
+	return true
+}
+
+// Connect plugs a peer into the other end of the link cable
+func (s *Serial) Connect(peer Peer) {
+	s.peer = peer
+}
+
+// ConnectRemote plugs a remote peer into the other end of the link cable
+func (s *Serial) ConnectRemote(peer RemotePeer) {
+	s.remote = peer
+}
+
+// Reply completes a transfer sent to a remote peer with the byte shifted back. Replies that arrive
+// after the transfer timed out are ignored.
+func (s *Serial) Reply(in uint8) {
+	if s.waiting == 0 {
 		return
 	}
-	_, err := s.writer.Write([]byte{value})
-	if err != nil {
-		panic(fmt.Sprintf("Write to SB failed: %v", err))
+	s.waiting = 0
+	s.sb = in
+	s.sc &^= 0x80
+	s.interrupt = true
+}
+
+// Exchange handles a byte clocked in by the peer's internal clock and returns the byte shifted out in
+// return. Only a transfer waiting for an external clock takes part, otherwise the line reads high.
+func (s *Serial) Exchange(in uint8) uint8 {
+	if s.sc&0x81 != 0x80 {
+		return 0xff
 	}
+	out := s.sb
+	s.sb = in
+	s.sc &^= 0x80
+	s.interrupt = true
+	return out
+}
+
+// WriteSB handles writes to register SB
+func (s *Serial) WriteSB(value uint8) {
+	// fmt.Printf("> SB - 0x%02x\n", value)
+	s.sb = value
 }
 
 // ReadSB handles reads from register SB
 func (s *Serial) ReadSB() uint8 {
-	// fmt.Printf("< SB - 0xff\n")
-	return 0xff // FIXME implement serial support
+	// fmt.Printf("< SB - 0x%02x\n", s.sb)
+	return s.sb
 }
 
 // WriteSC handles writes to register SC
 func (s *Serial) WriteSC(value uint8) {
 	// fmt.Printf("> SC - 0x%02x\n", value)
-	// FIXME implement serial support
+	//   Bit 7 - Transfer Start Flag (0=No transfer is in progress or requested, 1=Transfer in progress, or requested)
+	//   Bit 0 - Shift Clock (0=External Clock, 1=Internal Clock)
+	s.sc = value & 0x81
+	s.bits = 0
+	s.waiting = 0
+	if s.sc == 0x81 && s.writer != nil {
+		_, err := s.writer.Write([]byte{s.sb})
+		if err != nil {
+			panic(fmt.Sprintf("Write to SB failed: %v", err))
+		}
+	}
 }
 
 // ReadSC handles reads from register SC
 func (s *Serial) ReadSC() uint8 {
-	// fmt.Printf("< SC - 0xff\n")
-	return 0xff // FIXME implement serial support
+	// fmt.Printf("< SC - 0x%02x\n", s.sc|0x7e)
+	return s.sc | 0x7e
 }
@@ -0,0 +1,68 @@
+package serial
+
+import (
+	"bytes"
+	"testing"
+)
+
+// An internal clock transfer shifts one bit every 128 machine cycles
+const cyclesPerBit = 128
+
+// mticks runs the serial bus alongside a counter that starts from zero like DIV after a reset
+func mticks(serial *Serial, counter *uint16, mticks int) bool {
+	var interrupt bool
+	for i := 0; i < mticks; i++ {
+		*counter += 4
+		interrupt = serial.EndMachineCycle(*counter) || interrupt
+	}
+	return interrupt
+}
+
+func TestInternalClockTransfer(t *testing.T) {
+	writer := &bytes.Buffer{}
+	serial := New(writer)
+	var counter uint16
+	serial.WriteSB(0x42)
+	serial.WriteSC(0x81)
+	if serial.ReadSC() != 0xff {
+		t.Errorf("Wrong SC during transfer: 0x%02x", serial.ReadSC())
+	}
+	if mticks(serial, &counter, 8*cyclesPerBit-1) {
+		t.Error("Transfer completed too early")
+	}
+	if serial.ReadSB() != 0x7f {
+		t.Errorf("Wrong SB during transfer: 0x%02x", serial.ReadSB())
+	}
+	if !mticks(serial, &counter, 1) {
+		t.Error("Transfer did not complete")
+	}
+	if serial.ReadSC() != 0x7f {
+		t.Errorf("Wrong SC after transfer: 0x%02x", serial.ReadSC())
+	}
+	if serial.ReadSB() != 0xff {
+		t.Errorf("Wrong SB after transfer: 0x%02x", serial.ReadSB())
+	}
+	if !bytes.Equal(writer.Bytes(), []byte{0x42}) {
+		t.Errorf("Wrong bytes written: %v", writer.Bytes())
+	}
+}
+
+func TestExternalClockTransfer(t *testing.T) {
+	writer := &bytes.Buffer{}
+	serial := New(writer)
+	var counter uint16
+	serial.WriteSB(0x42)
+	serial.WriteSC(0x80)
+	if mticks(serial, &counter, 100*cyclesPerBit) {
+		t.Error("Transfer completed without a clock")
+	}
+	if serial.ReadSC() != 0xfe {
+		t.Errorf("Wrong SC while waiting: 0x%02x", serial.ReadSC())
+	}
+	if serial.ReadSB() != 0x42 {
+		t.Errorf("Wrong SB while waiting: 0x%02x", serial.ReadSB())
+	}
+	if writer.Len() != 0 {
+		t.Errorf("Wrong bytes written: %v", writer.Bytes())
+	}
+}
@@ -0,0 +1,43 @@
+package serial
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// state is the serialisable form of the serial bus
+type state struct {
+	SB        uint8
+	SC        uint8
+	Clock     bool
+	Bits      uint8
+	Interrupt bool
+}
+
+// SaveState writes the serial bus state
+func (s *Serial) SaveState(w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, &state{
+		SB:        s.sb,
+		SC:        s.sc,
+		Clock:     s.clock,
+		Bits:      uint8(s.bits),
+		Interrupt: s.interrupt,
+	})
+}
+
+// LoadState restores the serial bus state
+func (s *Serial) LoadState(r io.Reader) error {
+	var st state
+	err := binary.Read(r, binary.LittleEndian, &st)
+	if err != nil {
+		return err
+	}
+	s.sb = st.SB
+	s.sc = st.SC
+	s.clock = st.Clock
+	s.bits = int(st.Bits)
+	s.interrupt = st.Interrupt
+	// Any reply to a transfer sent before the state was loaded no longer applies
+	s.waiting = 0
+	return nil
+}
@@ -0,0 +1,354 @@
+package sgb
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+
+	"github.com/scottyw/tetromino/gameboy/ppu"
+)
+
+// SGB command codes received in packets sent through JOYP
+const (
+	PAL01   = 0x00
+	PAL23   = 0x01
+	PAL03   = 0x02
+	PAL12   = 0x03
+	ATTRBLK = 0x04
+	ATTRLIN = 0x05
+	ATTRDIV = 0x06
+	ATTRCHR = 0x07
+	PALSET  = 0x0a
+	PALTRN  = 0x0b
+	MLTREQ  = 0x11
+	CHRTRN  = 0x13
+	PCTTRN  = 0x14
+	MASKEN  = 0x17
+)
+
+// The SGB screen is 256x224 with the Game Boy screen in the middle of the border
+const (
+	Width   = 256
+	Height  = 224
+	screenX = 48
+	screenY = 40
+)
+
+// SGB colourises the Game Boy screen using four palettes assigned to 8x8 areas of the screen by the
+// attribute map, and surrounds it with a border made of SNES tiles
+type SGB struct {
+	ppu            *ppu.PPU
+	frame          *image.RGBA
+	palettes       [4][4]uint16
+	systemPalettes [512][4]uint16
+	attributes     [18][20]uint8
+	borderTiles    [0x2000]byte
+	borderMap      [0x800]byte
+	borderPalettes [4][16]uint16
+	mask           uint8
+	transfer       uint8
+	transferParam  uint8
+}
+
+// New returns an SGB which colourises frames from the given PPU
+func New(ppu *ppu.PPU) *SGB {
+	sgb := &SGB{
+		ppu:   ppu,
+		frame: image.NewRGBA(image.Rect(0, 0, Width, Height)),
+	}
+	// Shades of grey until the game sends its own palettes
+	for i := range sgb.palettes {
+		sgb.palettes[i] = [4]uint16{0x7fff, 0x56b5, 0x294a, 0x0000}
+	}
+	return sgb
+}
+
+// Command carries out a command assembled from one or more 16-byte packets
+func (sgb *SGB) Command(data []byte) {
+	switch data[0] >> 3 {
+	case PAL01:
+		sgb.setPalettes(0, 1, data)
+	case PAL23:
+		sgb.setPalettes(2, 3, data)
+	case PAL03:
+		sgb.setPalettes(0, 3, data)
+	case PAL12:
+		sgb.setPalettes(1, 2, data)
+	case ATTRBLK:
+		sgb.attrBlk(data)
+	case ATTRLIN:
+		sgb.attrLin(data)
+	case ATTRDIV:
+		sgb.attrDiv(data)
+	case ATTRCHR:
+		sgb.attrChr(data)
+	case PALSET:
+		sgb.palSet(data)
+	case PALTRN, CHRTRN, PCTTRN:
+		// VRAM transfers read whatever is on screen by the end of the frame
+		sgb.transfer = data[0] >> 3
+		sgb.transferParam = data[1]
+	case MASKEN:
+		sgb.mask = data[1] & 0x03
+	}
+}
+
+// EndFrame completes any pending VRAM transfer and colourises the frame that the PPU just finished
+func (sgb *SGB) EndFrame() {
+	if sgb.transfer != 0 {
+		sgb.vramTransfer(sgb.transfer, sgb.transferParam, sgb.ppu.VRAMTransfer())
+		sgb.transfer = 0
+	}
+	backdrop := rgb(sgb.palettes[0][0])
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			if x >= screenX && x < screenX+160 && y >= screenY && y < screenY+144 {
+				sgb.renderScreenPixel(x-screenX, y-screenY)
+			} else {
+				sgb.frame.SetRGBA(x, y, backdrop)
+			}
+		}
+	}
+	sgb.renderBorder()
+}
+
+// Frame returns the most recently colourised frame including the border
+func (sgb *SGB) Frame() *image.RGBA {
+	return sgb.frame
+}
+
+// renderScreenPixel colourises a pixel of the Game Boy screen unless the screen is masked
+//
+//	MASK_EN 0  Cancel mask
+//	MASK_EN 1  Freeze the screen
+//	MASK_EN 2  Black screen
+//	MASK_EN 3  Screen filled with colour 0
+func (sgb *SGB) renderScreenPixel(x, y int) {
+	var c color.RGBA
+	switch sgb.mask {
+	case 0:
+		c = rgb(sgb.palettes[sgb.attributes[y/8][x/8]][sgb.ppu.Shade(x, y)])
+	case 1:
+		return
+	case 2:
+		c = rgb(0)
+	case 3:
+		c = rgb(sgb.palettes[0][0])
+	}
+	sgb.frame.SetRGBA(screenX+x, screenY+y, c)
+}
+
+// renderBorder draws the 32x28 tile border over the screen where its colour isn't transparent. Each
+// map entry chooses a tile, one of border palettes 4-7 and whether the tile is flipped.
+func (sgb *SGB) renderBorder() {
+	for ty := 0; ty < Height/8; ty++ {
+		for tx := 0; tx < Width/8; tx++ {
+			entry := binary.LittleEndian.Uint16(sgb.borderMap[(ty*32+tx)*2:])
+			tile := sgb.borderTiles[int(entry&0xff)*32:]
+			palette := (entry >> 10) & 0x03
+			for py := 0; py < 8; py++ {
+				row := py
+				if entry&0x8000 != 0 {
+					row = 7 - py
+				}
+				for px := 0; px < 8; px++ {
+					bit := uint(7 - px)
+					if entry&0x4000 != 0 {
+						bit = uint(px)
+					}
+					// SNES tiles are four bit planes interleaved as two pairs
+					c := tile[row*2]>>bit&1 |
+						tile[row*2+1]>>bit&1<<1 |
+						tile[16+row*2]>>bit&1<<2 |
+						tile[16+row*2+1]>>bit&1<<3
+					if c != 0 {
+						sgb.frame.SetRGBA(tx*8+px, ty*8+py, rgb(sgb.borderPalettes[palette][c]))
+					}
+				}
+			}
+		}
+	}
+}
+
+// setPalettes sets two palettes from a PAL01, PAL23, PAL03 or PAL12 packet. Colour 0 is shared by
+// all palettes.
+func (sgb *SGB) setPalettes(a, b int, data []byte) {
+	colour0 := binary.LittleEndian.Uint16(data[1:])
+	for i := range sgb.palettes {
+		sgb.palettes[i][0] = colour0
+	}
+	for i := 1; i < 4; i++ {
+		sgb.palettes[a][i] = binary.LittleEndian.Uint16(data[1+i*2:])
+		sgb.palettes[b][i] = binary.LittleEndian.Uint16(data[7+i*2:])
+	}
+}
+
+// palSet copies four of the system palettes received by PAL_TRN into the active palettes
+func (sgb *SGB) palSet(data []byte) {
+	for i := range sgb.palettes {
+		sgb.palettes[i] = sgb.systemPalettes[binary.LittleEndian.Uint16(data[1+i*2:])&0x1ff]
+	}
+	for i := range sgb.palettes {
+		sgb.palettes[i][0] = sgb.palettes[0][0]
+	}
+	if data[9]&0x40 != 0 {
+		sgb.mask = 0
+	}
+}
+
+// attrBlk assigns palettes inside, on the edge of and outside up to 18 rectangles
+//
+//	Byte 0  Control (bit 0 inside, bit 1 border, bit 2 outside)
+//	Byte 1  Palettes (bits 0-1 inside, bits 2-3 border, bits 4-5 outside)
+//	Byte 2  X1
+//	Byte 3  Y1
+//	Byte 4  X2
+//	Byte 5  Y2
+func (sgb *SGB) attrBlk(data []byte) {
+	count := int(data[1] & 0x1f)
+	for i := 0; i < count && 8+i*6 <= len(data); i++ {
+		set := data[2+i*6 : 8+i*6]
+		control := set[0] & 0x07
+		inside := set[1] & 0x03
+		border := (set[1] >> 2) & 0x03
+		outside := (set[1] >> 4) & 0x03
+		// The border takes the palette of the inside or outside when only one of those is given
+		switch control {
+		case 0x01:
+			control |= 0x02
+			border = inside
+		case 0x04:
+			control |= 0x02
+			border = outside
+		}
+		x1, y1, x2, y2 := int(set[2]), int(set[3]), int(set[4]), int(set[5])
+		for y := range sgb.attributes {
+			for x := range sgb.attributes[y] {
+				switch {
+				case x > x1 && x < x2 && y > y1 && y < y2:
+					if control&0x01 != 0 {
+						sgb.attributes[y][x] = inside
+					}
+				case x >= x1 && x <= x2 && y >= y1 && y <= y2:
+					if control&0x02 != 0 {
+						sgb.attributes[y][x] = border
+					}
+				default:
+					if control&0x04 != 0 {
+						sgb.attributes[y][x] = outside
+					}
+				}
+			}
+		}
+	}
+}
+
+// attrLin assigns palettes to whole rows or columns
+//
+//	Bit 0-4  Line number
+//	Bit 5-6  Palette
+//	Bit 7    Direction (0=Vertical, 1=Horizontal)
+func (sgb *SGB) attrLin(data []byte) {
+	count := int(data[1])
+	for i := 0; i < count && 2+i < len(data); i++ {
+		line := int(data[2+i] & 0x1f)
+		palette := (data[2+i] >> 5) & 0x03
+		if data[2+i]&0x80 != 0 {
+			if line < 18 {
+				for x := range sgb.attributes[line] {
+					sgb.attributes[line][x] = palette
+				}
+			}
+		} else if line < 20 {
+			for y := range sgb.attributes {
+				sgb.attributes[y][line] = palette
+			}
+		}
+	}
+}
+
+// attrDiv divides the screen in two along a row or column
+//
+//	Bit 0-1  Palette right of or below the line
+//	Bit 2-3  Palette left of or above the line
+//	Bit 4-5  Palette on the line
+//	Bit 6    Direction (0=Vertical, 1=Horizontal)
+func (sgb *SGB) attrDiv(data []byte) {
+	after := data[1] & 0x03
+	before := (data[1] >> 2) & 0x03
+	on := (data[1] >> 4) & 0x03
+	horizontal := data[1]&0x40 != 0
+	line := int(data[2])
+	for y := range sgb.attributes {
+		for x := range sgb.attributes[y] {
+			position := x
+			if horizontal {
+				position = y
+			}
+			switch {
+			case position < line:
+				sgb.attributes[y][x] = before
+			case position == line:
+				sgb.attributes[y][x] = on
+			default:
+				sgb.attributes[y][x] = after
+			}
+		}
+	}
+}
+
+// attrChr assigns palettes to individual tiles starting from a position, with four tiles per byte
+// starting at the high bits, moving either left-to-right or top-to-bottom
+func (sgb *SGB) attrChr(data []byte) {
+	x, y := int(data[1]), int(data[2])
+	count := int(binary.LittleEndian.Uint16(data[3:]))
+	vertical := data[5] != 0
+	for i := 0; i < count && 6+i/4 < len(data); i++ {
+		if x >= 20 || y >= 18 {
+			return
+		}
+		sgb.attributes[y][x] = (data[6+i/4] >> uint(6-2*(i%4))) & 0x03
+		if vertical {
+			y++
+			if y == 18 {
+				y = 0
+				x++
+			}
+		} else {
+			x++
+			if x == 20 {
+				x = 0
+				y++
+			}
+		}
+	}
+}
+
+// vramTransfer receives 4KB of data copied from the screen
+func (sgb *SGB) vramTransfer(command, param uint8, data []byte) {
+	switch command {
+	case PALTRN:
+		for i := range sgb.systemPalettes {
+			for c := range sgb.systemPalettes[i] {
+				sgb.systemPalettes[i][c] = binary.LittleEndian.Uint16(data[i*8+c*2:])
+			}
+		}
+	case CHRTRN:
+		copy(sgb.borderTiles[int(param&0x01)*0x1000:], data)
+	case PCTTRN:
+		copy(sgb.borderMap[:], data)
+		for i := range sgb.borderPalettes {
+			for c := range sgb.borderPalettes[i] {
+				sgb.borderPalettes[i][c] = binary.LittleEndian.Uint16(data[0x800+i*32+c*2:])
+			}
+		}
+	}
+}
+
+// rgb converts an SNES colour from RGB555 to RGBA
+func rgb(rgb555 uint16) color.RGBA {
+	r := uint8(rgb555 & 0x1f)
+	g := uint8((rgb555 >> 5) & 0x1f)
+	b := uint8((rgb555 >> 10) & 0x1f)
+	return color.RGBA{r<<3 | r>>2, g<<3 | g>>2, b<<3 | b>>2, 0xff}
+}
@@ -0,0 +1,168 @@
+package sgb
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/scottyw/tetromino/gameboy/interrupts"
+	"github.com/scottyw/tetromino/gameboy/oam"
+	"github.com/scottyw/tetromino/gameboy/ppu"
+)
+
+func newSGB() *SGB {
+	return New(ppu.New(interrupts.New(), oam.New(), false, false))
+}
+
+func packet(command uint8, data ...byte) []byte {
+	p := make([]byte, 16)
+	p[0] = command<<3 | 1
+	copy(p[1:], data)
+	return p
+}
+
+func TestPalettes(t *testing.T) {
+	sgb := newSGB()
+	sgb.Command(packet(PAL12, 0x1f, 0x00, 0x01, 0x00, 0x02, 0x00, 0x03, 0x00, 0x04, 0x00, 0x05, 0x00, 0x06, 0x00))
+	expected := [4][4]uint16{
+		{0x001f, 0x56b5, 0x294a, 0x0000},
+		{0x001f, 0x0001, 0x0002, 0x0003},
+		{0x001f, 0x0004, 0x0005, 0x0006},
+		{0x001f, 0x56b5, 0x294a, 0x0000},
+	}
+	if sgb.palettes != expected {
+		t.Errorf("expected palettes %v but got %v", expected, sgb.palettes)
+	}
+	sgb.EndFrame()
+	if sgb.Frame().RGBAAt(0, 0) != (color.RGBA{0xff, 0, 0, 0xff}) {
+		t.Errorf("expected a red backdrop but got %v", sgb.Frame().RGBAAt(0, 0))
+	}
+}
+
+func TestPalSet(t *testing.T) {
+	sgb := newSGB()
+	data := make([]byte, 0x1000)
+	for i := 0; i < 0x1000; i += 2 {
+		data[i] = uint8(i / 8)
+		data[i+1] = uint8(i / 8 >> 8)
+	}
+	sgb.vramTransfer(PALTRN, 0, data)
+	sgb.mask = 2
+	sgb.Command(packet(PALSET, 0x00, 0x01, 0x01, 0x00, 0xff, 0x01, 0x05, 0x00, 0x40))
+	expected := [4][4]uint16{
+		{0x0100, 0x0100, 0x0100, 0x0100},
+		{0x0100, 0x0001, 0x0001, 0x0001},
+		{0x0100, 0x01ff, 0x01ff, 0x01ff},
+		{0x0100, 0x0005, 0x0005, 0x0005},
+	}
+	if sgb.palettes != expected {
+		t.Errorf("expected palettes %v but got %v", expected, sgb.palettes)
+	}
+	if sgb.mask != 0 {
+		t.Errorf("expected the mask to be cancelled")
+	}
+}
+
+func TestAttrBlk(t *testing.T) {
+	sgb := newSGB()
+	// Inside palette 1 on its own also colours the border
+	sgb.Command(packet(ATTRBLK, 0x02, 0x01, 0x01, 0x01, 0x01, 0x03, 0x03, 0x02, 0x08, 0x04, 0x00, 0x13, 0x00))
+	// Outside palette 3 on its own also colours the border
+	sgb.Command(packet(ATTRBLK, 0x01, 0x04, 0x30, 0x00, 0x00, 0x13, 0x10))
+	for _, test := range []struct {
+		x, y    int
+		palette uint8
+	}{
+		{1, 1, 1},
+		{2, 2, 1},
+		{3, 3, 1},
+		{4, 1, 0},
+		{0, 0, 3},
+		{4, 0, 3},
+		{0, 5, 3},
+		{19, 17, 3},
+	} {
+		if sgb.attributes[test.y][test.x] != test.palette {
+			t.Errorf("expected palette %d at (%d,%d) but got %d", test.palette, test.x, test.y, sgb.attributes[test.y][test.x])
+		}
+	}
+}
+
+func TestAttrLin(t *testing.T) {
+	sgb := newSGB()
+	sgb.Command(packet(ATTRLIN, 0x02, 0x85, 0x43))
+	if sgb.attributes[5][10] != 0 || sgb.attributes[10][3] != 2 || sgb.attributes[5][3] != 2 || sgb.attributes[4][0] != 0 {
+		t.Errorf("unexpected attributes %v", sgb.attributes)
+	}
+	sgb.Command(packet(ATTRLIN, 0x01, 0xa5))
+	if sgb.attributes[5][10] != 1 || sgb.attributes[5][3] != 1 {
+		t.Errorf("unexpected attributes %v", sgb.attributes)
+	}
+}
+
+func TestAttrDiv(t *testing.T) {
+	sgb := newSGB()
+	sgb.Command(packet(ATTRDIV, 0x5b, 0x09))
+	if sgb.attributes[8][0] != 2 || sgb.attributes[9][19] != 1 || sgb.attributes[10][5] != 3 {
+		t.Errorf("unexpected attributes %v", sgb.attributes)
+	}
+}
+
+func TestAttrChr(t *testing.T) {
+	sgb := newSGB()
+	sgb.Command(packet(ATTRCHR, 18, 2, 5, 0, 0, 0x1b, 0xc0))
+	for _, test := range []struct {
+		x, y    int
+		palette uint8
+	}{
+		{18, 2, 0},
+		{19, 2, 1},
+		{0, 3, 2},
+		{1, 3, 3},
+		{2, 3, 3},
+		{3, 3, 0},
+	} {
+		if sgb.attributes[test.y][test.x] != test.palette {
+			t.Errorf("expected palette %d at (%d,%d) but got %d", test.palette, test.x, test.y, sgb.attributes[test.y][test.x])
+		}
+	}
+}
+
+func TestBorder(t *testing.T) {
+	sgb := newSGB()
+	// Tile 1 has colour 15 in its top left pixel only
+	tiles := make([]byte, 0x1000)
+	tiles[32] = 0x80
+	tiles[33] = 0x80
+	tiles[48] = 0x80
+	tiles[49] = 0x80
+	sgb.vramTransfer(CHRTRN, 0, tiles)
+	// The second map entry uses tile 1 with palette 5 flipped in both directions
+	picture := make([]byte, 0x1000)
+	picture[2] = 0x01
+	picture[3] = 0xd4
+	picture[0x800+32+30] = 0x00
+	picture[0x800+32+31] = 0x7c
+	sgb.vramTransfer(PCTTRN, 0, picture)
+	sgb.EndFrame()
+	if sgb.Frame().RGBAAt(15, 7) != (color.RGBA{0, 0, 0xff, 0xff}) {
+		t.Errorf("expected a blue border pixel but got %v", sgb.Frame().RGBAAt(15, 7))
+	}
+	if sgb.Frame().RGBAAt(8, 0) != (color.RGBA{0xff, 0xff, 0xff, 0xff}) {
+		t.Errorf("expected a transparent border pixel but got %v", sgb.Frame().RGBAAt(8, 0))
+	}
+}
+
+func TestMask(t *testing.T) {
+	sgb := newSGB()
+	sgb.Command(packet(MASKEN, 0x02))
+	sgb.EndFrame()
+	if sgb.Frame().RGBAAt(screenX, screenY) != (color.RGBA{0, 0, 0, 0xff}) {
+		t.Errorf("expected a black screen but got %v", sgb.Frame().RGBAAt(screenX, screenY))
+	}
+	sgb.Command(packet(MASKEN, 0x01))
+	sgb.Command(packet(MASKEN, 0x00))
+	sgb.EndFrame()
+	if sgb.Frame().RGBAAt(screenX, screenY) != (color.RGBA{0xff, 0xff, 0xff, 0xff}) {
+		t.Errorf("expected a white screen but got %v", sgb.Frame().RGBAAt(screenX, screenY))
+	}
+}
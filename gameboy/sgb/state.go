@@ -0,0 +1,53 @@
+package sgb
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// state is the serialisable form of the SGB
+type state struct {
+	Palettes       [4][4]uint16
+	SystemPalettes [512][4]uint16
+	Attributes     [18][20]uint8
+	BorderTiles    [0x2000]byte
+	BorderMap      [0x800]byte
+	BorderPalettes [4][16]uint16
+	Mask           uint8
+	Transfer       uint8
+	TransferParam  uint8
+}
+
+// SaveState writes the SGB palettes, attribute map and border
+func (sgb *SGB) SaveState(w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, &state{
+		Palettes:       sgb.palettes,
+		SystemPalettes: sgb.systemPalettes,
+		Attributes:     sgb.attributes,
+		BorderTiles:    sgb.borderTiles,
+		BorderMap:      sgb.borderMap,
+		BorderPalettes: sgb.borderPalettes,
+		Mask:           sgb.mask,
+		Transfer:       sgb.transfer,
+		TransferParam:  sgb.transferParam,
+	})
+}
+
+// LoadState restores the SGB palettes, attribute map and border
+func (sgb *SGB) LoadState(r io.Reader) error {
+	var s state
+	err := binary.Read(r, binary.LittleEndian, &s)
+	if err != nil {
+		return err
+	}
+	sgb.palettes = s.Palettes
+	sgb.systemPalettes = s.SystemPalettes
+	sgb.attributes = s.Attributes
+	sgb.borderTiles = s.BorderTiles
+	sgb.borderMap = s.BorderMap
+	sgb.borderPalettes = s.BorderPalettes
+	sgb.mask = s.Mask
+	sgb.transfer = s.Transfer
+	sgb.transferParam = s.TransferParam
+	return nil
+}
@@ -0,0 +1,84 @@
+package gameboy
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/scottyw/tetromino/gameboy/controller"
+	"github.com/scottyw/tetromino/gameboy/sgb"
+)
+
+func newSGB() *Gameboy {
+	return New(Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		SGB:                true,
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	})
+}
+
+// sendPacket writes a packet to JOYP one bit at a time the way SGB games do
+func sendPacket(gb *Gameboy, packet [16]byte) {
+	gb.mapper.Write(0xff00, 0x00)
+	gb.mapper.Write(0xff00, 0x30)
+	for i := 0; i < 128; i++ {
+		if packet[i/8]&(1<<uint(i%8)) != 0 {
+			gb.mapper.Write(0xff00, 0x10)
+		} else {
+			gb.mapper.Write(0xff00, 0x20)
+		}
+		gb.mapper.Write(0xff00, 0x30)
+	}
+	gb.mapper.Write(0xff00, 0x20)
+	gb.mapper.Write(0xff00, 0x30)
+}
+
+func TestSGBPacket(t *testing.T) {
+	gb := newSGB()
+	if gb.screen().Bounds().Dx() != sgb.Width || gb.screen().Bounds().Dy() != sgb.Height {
+		t.Fatalf("expected a %dx%d screen", sgb.Width, sgb.Height)
+	}
+	sendPacket(gb, [16]byte{sgb.PAL01<<3 | 1, 0x00, 0x7c})
+	gb.endFrame()
+	if gb.screen().RGBAAt(0, 0) != (color.RGBA{0, 0, 0xff, 0xff}) {
+		t.Errorf("expected a blue backdrop but got %v", gb.screen().RGBAAt(0, 0))
+	}
+}
+
+func TestSGBMultiplayer(t *testing.T) {
+	gb := newSGB()
+	if gb.mapper.Read(0xff00)&0x0f != 0x0f {
+		t.Errorf("expected no joypad ID before MLT_REQ")
+	}
+	sendPacket(gb, [16]byte{sgb.MLTREQ<<3 | 1, 0x03})
+	gb.PlayerButtonAction(2, controller.A, true)
+	for _, expected := range []uint8{0x0f, 0x0e, 0x0d, 0x0c, 0x0f} {
+		id := gb.mapper.Read(0xff00) & 0x0f
+		if id != expected {
+			t.Errorf("expected joypad ID 0x%x but got 0x%x", expected, id)
+		}
+		gb.mapper.Write(0xff00, 0x10)
+		buttons := gb.mapper.Read(0xff00) & 0x0f
+		if (expected == 0x0d) != (buttons == 0x0e) {
+			t.Errorf("unexpected buttons 0x%x on joypad ID 0x%x", buttons, expected)
+		}
+		gb.mapper.Write(0xff00, 0x30)
+	}
+}
+
+func TestSGBIgnoresMissingPlayers(t *testing.T) {
+	gb := newSGB()
+
+	// Only one joypad is connected until the game sends MLT_REQ
+	gb.PlayerButtonAction(1, controller.A, true)
+	gb.PlayerButtonAction(-1, controller.A, true)
+	gb.PlayerButtonAction(4, controller.A, true)
+	sendPacket(gb, [16]byte{sgb.MLTREQ<<3 | 1, 0x01})
+	for i := 0; i < 2; i++ {
+		gb.mapper.Write(0xff00, 0x10)
+		if buttons := gb.mapper.Read(0xff00) & 0x0f; buttons != 0x0f {
+			t.Errorf("unexpected buttons 0x%x on joypad %d", buttons, i+1)
+		}
+		gb.mapper.Write(0xff00, 0x30)
+	}
+}
@@ -2,6 +2,7 @@ package speakers
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/gordonklaus/portaudio"
 )
@@ -13,17 +14,29 @@ type Speakers struct {
 	r      chan float32
 }
 
-// NewPortaudioSpeakers starts audio output using portaudio
-func New() *Speakers {
+// DefaultBufferSize is the number of samples queued for each speaker unless another size is chosen
+const DefaultBufferSize = 200
+
+// New starts audio output using portaudio at the given sample rate. Up to bufferSize samples are
+// queued for each speaker, or DefaultBufferSize when it's zero. The latency defaults to the output
+// device's low latency when it's zero.
+func New(sampleRate, bufferSize int, latency time.Duration) *Speakers {
+	if bufferSize == 0 {
+		bufferSize = DefaultBufferSize
+	}
 	portaudio.Initialize()
 	host, err := portaudio.DefaultHostApi()
 	if err != nil {
 		panic(fmt.Sprintf("Failed to create speakers: %v", err))
 	}
 	parameters := portaudio.LowLatencyParameters(nil, host.DefaultOutputDevice)
+	parameters.SampleRate = float64(sampleRate)
+	if latency > 0 {
+		parameters.Output.Latency = latency
+	}
 	speakers := &Speakers{
-		l: make(chan float32, 200),
-		r: make(chan float32, 200),
+		l: make(chan float32, bufferSize),
+		r: make(chan float32, bufferSize),
 	}
 	stream, err := portaudio.OpenStream(parameters, speakers.Callback)
 	if err != nil {
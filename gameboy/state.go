@@ -0,0 +1,146 @@
+package gameboy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Save states start with a magic string and a format version so that old or
+// foreign files are rejected rather than misread. The version must be bumped
+// whenever the state of any subsystem changes shape.
+const (
+	stateMagic   = "TETROMINO"
+	stateVersion = uint16(11)
+)
+
+// stateHeader identifies the save state format and the ROM it was taken from
+type stateHeader struct {
+	Magic   [9]byte
+	Version uint16
+	ROMHash uint32
+}
+
+// stateSubsystem is implemented by every part of the machine that holds state
+type stateSubsystem interface {
+	SaveState(w io.Writer) error
+	LoadState(r io.Reader) error
+}
+
+// subsystems returns the stateful parts of the machine in serialisation order
+func (gb *Gameboy) subsystems() []stateSubsystem {
+	subsystems := []stateSubsystem{
+		gb.cpu,
+		gb.interrupts,
+		gb.timer,
+		gb.ppu,
+		gb.oam,
+		gb.audio,
+		gb.controller,
+		gb.serial,
+		gb.mapper,
+	}
+	if gb.sgb != nil {
+		subsystems = append(subsystems, gb.sgb)
+	}
+	return subsystems
+}
+
+// SaveState writes a snapshot of the entire machine
+func (gb *Gameboy) SaveState(w io.Writer) error {
+	header := stateHeader{
+		Version: stateVersion,
+		ROMHash: gb.romHash,
+	}
+	copy(header.Magic[:], stateMagic)
+	err := binary.Write(w, binary.LittleEndian, &header)
+	if err != nil {
+		return err
+	}
+	for _, s := range gb.subsystems() {
+		err := s.SaveState(w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadState restores a snapshot of the entire machine previously written by
+// SaveState. If the snapshot can't be loaded the machine is left unchanged.
+func (gb *Gameboy) LoadState(r io.Reader) error {
+	var header stateHeader
+	err := binary.Read(r, binary.LittleEndian, &header)
+	if err != nil {
+		return err
+	}
+	if string(header.Magic[:]) != stateMagic {
+		return errors.New("not a save state")
+	}
+	if header.Version != stateVersion {
+		return fmt.Errorf("unsupported save state version: %d", header.Version)
+	}
+	if header.ROMHash != gb.romHash {
+		return errors.New("save state was taken from a different ROM")
+	}
+
+	// Keep a copy of the current state to roll back to if the snapshot is truncated or corrupt
+	backup := &bytes.Buffer{}
+	err = gb.SaveState(backup)
+	if err != nil {
+		return err
+	}
+	for _, s := range gb.subsystems() {
+		err := s.LoadState(r)
+		if err != nil {
+			if rollbackErr := gb.LoadState(backup); rollbackErr != nil {
+				panic(fmt.Sprintf("Failed to roll back save state (%v)", rollbackErr))
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// stateFilename returns the file used for a save state slot e.g. slot 1 for tetris.gb is tetris.ss1
+func (gb *Gameboy) stateFilename(slot int) string {
+	rom := gb.config.RomFilename
+	return fmt.Sprintf("%s.ss%d", strings.TrimSuffix(rom, filepath.Ext(rom)), slot)
+}
+
+func (gb *Gameboy) saveStateSlot(slot int) {
+	filename := gb.stateFilename(slot)
+	f, err := os.Create(filename)
+	if err != nil {
+		fmt.Printf("Failed to save state to \"%s\" (%v)\n", filename, err)
+		return
+	}
+	defer f.Close()
+	err = gb.SaveState(f)
+	if err != nil {
+		fmt.Printf("Failed to save state to \"%s\" (%v)\n", filename, err)
+		return
+	}
+	fmt.Printf("Saved state to slot %d\n", slot)
+}
+
+func (gb *Gameboy) loadStateSlot(slot int) {
+	filename := gb.stateFilename(slot)
+	f, err := os.Open(filename)
+	if err != nil {
+		fmt.Printf("Failed to load state from \"%s\" (%v)\n", filename, err)
+		return
+	}
+	defer f.Close()
+	err = gb.LoadState(f)
+	if err != nil {
+		fmt.Printf("Failed to load state from \"%s\" (%v)\n", filename, err)
+		return
+	}
+	fmt.Printf("Loaded state from slot %d\n", slot)
+}
@@ -0,0 +1,109 @@
+package gameboy
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func runFrames(gb *Gameboy, frames int) {
+	for i := 0; i < frames; i++ {
+		gb.runFrame(context.Background())
+	}
+}
+
+func TestSaveStateRoundTrip(t *testing.T) {
+	config := Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	}
+	gameboy := New(config)
+	runFrames(gameboy, 100)
+
+	// Snapshot part-way through and record where the machine gets to afterwards
+	snapshot := &bytes.Buffer{}
+	err := gameboy.SaveState(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runFrames(gameboy, 100)
+	expected := &bytes.Buffer{}
+	err = gameboy.SaveState(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedFrame := append([]byte(nil), gameboy.ppu.Frame().Pix...)
+
+	// Restoring the snapshot into a fresh machine must reproduce exactly the same run
+	restored := New(config)
+	err = restored.LoadState(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runFrames(restored, 100)
+	actual := &bytes.Buffer{}
+	err = restored.SaveState(actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expected.Bytes(), actual.Bytes()) {
+		t.Error("machine state differs after restoring save state")
+	}
+	if !bytes.Equal(expectedFrame, restored.ppu.Frame().Pix) {
+		t.Error("frame differs after restoring save state")
+	}
+}
+
+func TestLoadStateRejectsOtherROM(t *testing.T) {
+	gameboy := New(Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	})
+	other := New(Config{
+		RomFilename:        "testdata/blargg/halt_bug.gb",
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	})
+	snapshot := &bytes.Buffer{}
+	err := gameboy.SaveState(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.LoadState(snapshot) == nil {
+		t.Error("expected save state from a different ROM to be rejected")
+	}
+}
+
+func TestLoadStateTruncated(t *testing.T) {
+	config := Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	}
+	gameboy := New(config)
+	runFrames(gameboy, 10)
+	snapshot := &bytes.Buffer{}
+	err := gameboy.SaveState(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runFrames(gameboy, 10)
+	before := &bytes.Buffer{}
+	err = gameboy.SaveState(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gameboy.LoadState(bytes.NewReader(snapshot.Bytes()[:snapshot.Len()/2])) == nil {
+		t.Error("expected truncated save state to be rejected")
+	}
+	after := &bytes.Buffer{}
+	err = gameboy.SaveState(after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before.Bytes(), after.Bytes()) {
+		t.Error("machine state changed after failing to load save state")
+	}
+}
@@ -0,0 +1,56 @@
+package timer
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// state is the serialisable form of the timer
+type state struct {
+	Counter     uint16
+	TAC         uint8
+	TIMA        uint8
+	TMA         uint8
+	LastEdgeSet bool
+	TIMAWrite   bool
+	TMAWrite    bool
+	Overflow    bool
+	EndCycleA   uint16
+	EndCycleB   uint16
+}
+
+// SaveState writes the timer state
+func (t *Timer) SaveState(w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, &state{
+		Counter:     t.counter,
+		TAC:         t.tac,
+		TIMA:        t.tima,
+		TMA:         t.tma,
+		LastEdgeSet: t.lastEdgeSet,
+		TIMAWrite:   t.timaWrite,
+		TMAWrite:    t.tmaWrite,
+		Overflow:    t.overflow,
+		EndCycleA:   t.endCycleA,
+		EndCycleB:   t.endCycleB,
+	})
+}
+
+// LoadState restores the timer state
+func (t *Timer) LoadState(r io.Reader) error {
+	var s state
+	err := binary.Read(r, binary.LittleEndian, &s)
+	if err != nil {
+		return err
+	}
+	t.counter = s.Counter
+	t.tac = s.TAC
+	t.tima = s.TIMA
+	t.tma = s.TMA
+	t.lastEdgeSet = s.LastEdgeSet
+	t.timaWrite = s.TIMAWrite
+	t.tmaWrite = s.TMAWrite
+	t.overflow = s.Overflow
+	t.endCycleA = s.EndCycleA
+	t.endCycleB = s.EndCycleB
+	return nil
+}
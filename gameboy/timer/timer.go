@@ -28,6 +28,18 @@ func New() *Timer {
 	}
 }
 
+// PowerOn resets the timer to its state before the boot ROM runs
+func (t *Timer) PowerOn() {
+	// The counter doesn't start from zero on real hardware. This value is chosen so that the
+	// counter is 0xabcc when the DMG boot ROM hands over to the cartridge, just as it is on a DMG.
+	t.counter = 0x0008
+}
+
+// Counter returns the internal counter
+func (t *Timer) Counter() uint16 {
+	return t.counter
+}
+
 // EndMachineCycle updates the timer after a machine cycle
 func (t *Timer) EndMachineCycle() bool {
 	t.counter += 4
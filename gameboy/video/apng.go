@@ -0,0 +1,145 @@
+package video
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"os"
+)
+
+// apngRecorder streams frames to an animated PNG. Each frame is compressed by image/png and its
+// image data is moved into the frame data chunks that APNG adds to the format. The frame count in
+// the animation control chunk isn't known until the end so it's filled in when the file is closed.
+type apngRecorder struct {
+	f       *os.File
+	w       *bufio.Writer
+	encoder png.Encoder
+	frame   *image.RGBA
+	scale   int
+	frames  int
+	seq     uint32
+}
+
+// The animation control chunk follows the 8 byte PNG signature and the 25 byte IHDR chunk
+const actlOffset = 33
+
+func newAPNGRecorder(filename string, width, height, scale int) (*apngRecorder, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &apngRecorder{
+		f:       f,
+		w:       bufio.NewWriter(f),
+		encoder: png.Encoder{CompressionLevel: png.BestSpeed},
+		frame:   image.NewRGBA(image.Rect(0, 0, width*scale, height*scale)),
+		scale:   scale,
+	}, nil
+}
+
+// AddFrame appends a frame to the APNG
+func (r *apngRecorder) AddFrame(frame *image.RGBA) error {
+
+	// Every frame is drawn opaque so that image/png always chooses the same colour type
+	bounds := r.frame.Bounds()
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			c := frame.RGBAAt(frame.Rect.Min.X+x/r.scale, frame.Rect.Min.Y+y/r.scale)
+			c.A = 0xff
+			r.frame.SetRGBA(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	err := r.encoder.Encode(&buf, r.frame)
+	if err != nil {
+		return err
+	}
+	chunks := buf.Bytes()[8:]
+
+	// The signature, header and animation control chunk come before the first frame
+	if r.frames == 0 {
+		r.w.Write(buf.Bytes()[:8])
+		r.w.Write(chunks[:25])
+		r.writeChunk("acTL", make([]byte, 8))
+	}
+
+	// Frame control chunk with the frame delay in ten thousandths of a second
+	fctl := make([]byte, 26)
+	binary.BigEndian.PutUint32(fctl[0:], r.nextSeq())
+	binary.BigEndian.PutUint32(fctl[4:], uint32(bounds.Dx()))
+	binary.BigEndian.PutUint32(fctl[8:], uint32(bounds.Dy()))
+	binary.BigEndian.PutUint16(fctl[20:], uint16(frameDelay(r.frames, 10000)))
+	binary.BigEndian.PutUint16(fctl[22:], 10000)
+	r.writeChunk("fcTL", fctl)
+
+	// The first frame is also the default image so it keeps its IDAT chunks
+	for len(chunks) > 0 {
+		length := binary.BigEndian.Uint32(chunks)
+		chunkType := string(chunks[4:8])
+		data := chunks[8 : 8+length]
+		chunks = chunks[12+length:]
+		if chunkType != "IDAT" {
+			continue
+		}
+		if r.frames == 0 {
+			r.writeChunk("IDAT", data)
+		} else {
+			fdat := make([]byte, 4, 4+len(data))
+			binary.BigEndian.PutUint32(fdat, r.nextSeq())
+			r.writeChunk("fdAT", append(fdat, data...))
+		}
+	}
+
+	r.frames++
+	return nil
+}
+
+func (r *apngRecorder) nextSeq() uint32 {
+	seq := r.seq
+	r.seq++
+	return seq
+}
+
+// writeChunk writes a PNG chunk
+func (r *apngRecorder) writeChunk(chunkType string, data []byte) {
+	r.w.Write(chunk(chunkType, data))
+}
+
+// chunk returns a PNG chunk with its length and checksum
+func chunk(chunkType string, data []byte) []byte {
+	b := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(b, uint32(len(data)))
+	copy(b[4:], chunkType)
+	b = append(b, data...)
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.ChecksumIEEE(b[4:]))
+	return append(b, crc...)
+}
+
+// Close writes the end of the APNG, fills in the frame count and closes the file
+func (r *apngRecorder) Close() error {
+	err := r.finish()
+	if closeErr := r.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (r *apngRecorder) finish() error {
+	if r.frames == 0 {
+		return errors.New("no frames were recorded")
+	}
+	r.writeChunk("IEND", nil)
+	err := r.w.Flush()
+	if err != nil {
+		return err
+	}
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl, uint32(r.frames))
+	_, err = r.f.WriteAt(chunk("acTL", actl), actlOffset)
+	return err
+}
@@ -0,0 +1,166 @@
+package video
+
+import (
+	"bufio"
+	"bytes"
+	"compress/lzw"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"os"
+)
+
+// gifRecorder streams frames to an animated GIF as they arrive rather than holding the whole
+// animation in memory like image/gif does. DMG frames only have 4 colours so each frame gets a small
+// local colour table of exactly the colours it uses.
+type gifRecorder struct {
+	f             *os.File
+	w             *bufio.Writer
+	width, height int
+	scale         int
+	frames        int
+}
+
+func newGIFRecorder(filename string, width, height, scale int) (*gifRecorder, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	r := &gifRecorder{
+		f:      f,
+		w:      bufio.NewWriter(f),
+		width:  width * scale,
+		height: height * scale,
+		scale:  scale,
+	}
+	// Header and logical screen descriptor without a global colour table
+	r.w.WriteString("GIF89a")
+	binary.Write(r.w, binary.LittleEndian, []uint16{uint16(r.width), uint16(r.height)})
+	r.w.Write([]byte{0x00, 0x00, 0x00})
+	// Netscape application extension to loop forever
+	r.w.Write([]byte{0x21, 0xff, 0x0b})
+	r.w.WriteString("NETSCAPE2.0")
+	r.w.Write([]byte{0x03, 0x01, 0x00, 0x00, 0x00})
+	return r, nil
+}
+
+// AddFrame appends a frame to the GIF
+func (r *gifRecorder) AddFrame(frame *image.RGBA) error {
+	pixels, colours := r.index(frame)
+
+	// The colour table size must be a power of two and the LZW code size at least 2 bits
+	bits := 1
+	for 1<<bits < len(colours) {
+		bits++
+	}
+	codeSize := bits
+	if codeSize < 2 {
+		codeSize = 2
+	}
+
+	// Graphic control extension with the frame delay in hundredths of a second
+	r.w.Write([]byte{0x21, 0xf9, 0x04, 0x04})
+	binary.Write(r.w, binary.LittleEndian, uint16(frameDelay(r.frames, 100)))
+	r.w.Write([]byte{0x00, 0x00})
+
+	// Image descriptor and local colour table
+	r.w.WriteByte(0x2c)
+	binary.Write(r.w, binary.LittleEndian, []uint16{0, 0, uint16(r.width), uint16(r.height)})
+	r.w.WriteByte(0x80 | uint8(bits-1))
+	for i := 0; i < 1<<bits; i++ {
+		var c color.RGBA
+		if i < len(colours) {
+			c = colours[i]
+		}
+		r.w.Write([]byte{c.R, c.G, c.B})
+	}
+
+	// LZW compressed pixels split into sub-blocks of up to 255 bytes
+	var data bytes.Buffer
+	lw := lzw.NewWriter(&data, lzw.LSB, codeSize)
+	lw.Write(pixels)
+	lw.Close()
+	r.w.WriteByte(uint8(codeSize))
+	for data.Len() > 0 {
+		block := data.Next(255)
+		r.w.WriteByte(uint8(len(block)))
+		r.w.Write(block)
+	}
+	_, err := r.w.Write([]byte{0x00})
+
+	r.frames++
+	return err
+}
+
+// index returns the scaled frame as indexes into a list of the colours it uses. Frames with more
+// than 256 colours, which CGB games can manage by changing palettes mid-frame, use the nearest
+// colours in a fixed palette instead.
+func (r *gifRecorder) index(frame *image.RGBA) ([]byte, []color.RGBA) {
+	bounds := frame.Bounds()
+	indexes := map[color.RGBA]uint8{}
+	var colours []color.RGBA
+	original := make([]byte, bounds.Dx()*bounds.Dy())
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			c := frame.RGBAAt(bounds.Min.X+x, bounds.Min.Y+y)
+			index, ok := indexes[c]
+			if !ok {
+				if len(colours) == 256 {
+					return r.enlarge(r.quantize(frame), bounds.Dx()), plan9
+				}
+				index = uint8(len(colours))
+				indexes[c] = index
+				colours = append(colours, c)
+			}
+			original[y*bounds.Dx()+x] = index
+		}
+	}
+	return r.enlarge(original, bounds.Dx()), colours
+}
+
+var plan9 = func() []color.RGBA {
+	colours := make([]color.RGBA, len(palette.Plan9))
+	for i, c := range palette.Plan9 {
+		colours[i] = color.RGBAModel.Convert(c).(color.RGBA)
+	}
+	return colours
+}()
+
+// quantize returns the frame as indexes of the nearest colours in the Plan 9 palette
+func (r *gifRecorder) quantize(frame *image.RGBA) []byte {
+	bounds := frame.Bounds()
+	p := color.Palette(palette.Plan9)
+	pixels := make([]byte, bounds.Dx()*bounds.Dy())
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			pixels[y*bounds.Dx()+x] = uint8(p.Index(frame.RGBAAt(bounds.Min.X+x, bounds.Min.Y+y)))
+		}
+	}
+	return pixels
+}
+
+// enlarge enlarges the pixels of a frame with the given width by the scale factor
+func (r *gifRecorder) enlarge(pixels []byte, width int) []byte {
+	if r.scale == 1 {
+		return pixels
+	}
+	scaled := make([]byte, 0, r.width*r.height)
+	for y := 0; y < r.height; y++ {
+		row := pixels[(y/r.scale)*width : (y/r.scale+1)*width]
+		for x := 0; x < r.width; x++ {
+			scaled = append(scaled, row[x/r.scale])
+		}
+	}
+	return scaled
+}
+
+// Close writes the GIF trailer and closes the file
+func (r *gifRecorder) Close() error {
+	r.w.WriteByte(0x3b)
+	err := r.w.Flush()
+	if closeErr := r.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,48 @@
+package video
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"strings"
+)
+
+// The LCD shows a new frame every 70224 cycles of the 4194304Hz clock, which is about 59.73 frames
+// per second
+const (
+	clockSpeed     = 4194304
+	cyclesPerFrame = 70224
+)
+
+// Recorder writes frames to an animated image file
+type Recorder interface {
+	// AddFrame appends a frame to the animation
+	AddFrame(frame *image.RGBA) error
+	// Close finishes the animation file
+	Close() error
+}
+
+// New returns a recorder writing frames of the given size to an animated GIF or APNG file depending
+// on whether the filename ends in ".gif" or ".png". Each frame is enlarged by the scale factor.
+func New(filename string, width, height, scale int) (Recorder, error) {
+	if scale < 1 {
+		scale = 1
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".gif":
+		return newGIFRecorder(filename, width, height, scale)
+	case ".png", ".apng":
+		return newAPNGRecorder(filename, width, height, scale)
+	default:
+		return nil, fmt.Errorf("unsupported video format %q (use .gif or .png)", filepath.Ext(filename))
+	}
+}
+
+// frameDelay returns how long to show a frame in units of the given fraction of a second. Delays
+// are rounded so that the total length of the animation stays true to the real frame rate.
+func frameDelay(frame, unitsPerSecond int) int {
+	end := func(frame int) int {
+		return (frame*cyclesPerFrame*unitsPerSecond + clockSpeed/2) / clockSpeed
+	}
+	return end(frame+1) - end(frame)
+}
@@ -0,0 +1,201 @@
+package video
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testColours = []color.RGBA{
+	{0xe0, 0xf8, 0xd0, 0xff},
+	{0x88, 0xc0, 0x70, 0xff},
+	{0x34, 0x68, 0x56, 0xff},
+	{0x08, 0x18, 0x20, 0xff},
+}
+
+// testFrame returns a 4x2 frame with a column of each test colour, shifted left by the frame number
+func testFrame(frame int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			img.SetRGBA(x, y, testColours[(x+frame)%4])
+		}
+	}
+	return img
+}
+
+func record(t *testing.T, filename string, scale int, frames ...*image.RGBA) {
+	r, err := New(filename, 4, 2, scale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, frame := range frames {
+		err = r.AddFrame(frame)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "video")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestFrameDelay(t *testing.T) {
+	// Rounding errors don't build up so 262144 frames last exactly 4389 seconds
+	total := 0
+	for frame := 0; frame < 262144; frame++ {
+		delay := frameDelay(frame, 100)
+		if delay < 1 || delay > 2 {
+			t.Fatalf("frame %d: unexpected delay %d", frame, delay)
+		}
+		total += delay
+	}
+	if total != 438900 {
+		t.Errorf("expected 262144 frames to last 438900 hundredths of a second but found %d", total)
+	}
+}
+
+func TestGIF(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "test.gif")
+	record(t, filename, 3, testFrame(0), testFrame(1), testFrame(2))
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	g, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Image) != 3 || g.LoopCount != 0 {
+		t.Fatalf("expected 3 looping frames but found %d with loop count %d", len(g.Image), g.LoopCount)
+	}
+	if g.Delay[0]+g.Delay[1]+g.Delay[2] != 5 {
+		t.Errorf("expected 3 frames to last 5 hundredths of a second but found %v", g.Delay)
+	}
+	for i, img := range g.Image {
+		if img.Bounds() != image.Rect(0, 0, 12, 6) || len(img.Palette) != 4 {
+			t.Errorf("frame %d: expected 12x6 pixels in 4 colours but found %v in %d", i, img.Bounds(), len(img.Palette))
+		}
+		for x := 0; x < 12; x++ {
+			expected := testColours[(x/3+i)%4]
+			if color.RGBAModel.Convert(img.At(x, 5)) != expected {
+				t.Errorf("frame %d pixel (%d,5): expected %v but found %v", i, x, expected, img.At(x, 5))
+			}
+		}
+	}
+}
+
+func TestGIFManyColours(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "test.gif")
+	r, err := New(filename, 20, 20, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	for i := 0; i < 400; i++ {
+		frame.SetRGBA(i%20, i/20, color.RGBA{uint8(i), uint8(i >> 8), 0x80, 0xff})
+	}
+	if err := r.AddFrame(frame); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	g, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Image[0].Palette) != 256 {
+		t.Errorf("expected a 256 colour palette but found %d colours", len(g.Image[0].Palette))
+	}
+}
+
+func TestAPNG(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "test.png")
+	record(t, filename, 2, testFrame(0), testFrame(1), testFrame(2))
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Programs that don't support APNG show the first frame
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 8, 4) || color.RGBAModel.Convert(img.At(7, 3)) != testColours[3] {
+		t.Errorf("unexpected first frame %v", img.Bounds())
+	}
+
+	// Check the chunks are in the expected order with consecutive sequence numbers
+	var types []string
+	var seq uint32
+	var delay int
+	for chunks := data[8:]; len(chunks) > 0; {
+		length := binary.BigEndian.Uint32(chunks)
+		chunkType := string(chunks[4:8])
+		body := chunks[8 : 8+length]
+		chunks = chunks[12+length:]
+		types = append(types, chunkType)
+		switch chunkType {
+		case "acTL":
+			if binary.BigEndian.Uint32(body) != 3 {
+				t.Errorf("expected 3 frames but found %d", binary.BigEndian.Uint32(body))
+			}
+		case "fcTL", "fdAT":
+			if binary.BigEndian.Uint32(body) != seq {
+				t.Errorf("expected sequence number %d but found %d", seq, binary.BigEndian.Uint32(body))
+			}
+			seq++
+			if chunkType == "fcTL" {
+				delay += int(binary.BigEndian.Uint16(body[20:]))
+			}
+		}
+	}
+	expected := []string{"IHDR", "acTL", "fcTL", "IDAT", "fcTL", "fdAT", "fcTL", "fdAT", "IEND"}
+	if len(types) != len(expected) {
+		t.Fatalf("expected chunks %v but found %v", expected, types)
+	}
+	for i := range types {
+		if types[i] != expected[i] {
+			t.Fatalf("expected chunks %v but found %v", expected, types)
+		}
+	}
+	if delay != 502 {
+		t.Errorf("expected 3 frames to last 502 ten thousandths of a second but found %d", delay)
+	}
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	if _, err := New("video.avi", 160, 144, 1); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
@@ -0,0 +1,69 @@
+package gameboy
+
+import (
+	"testing"
+)
+
+func newAccessTest(allowVRAMAccess bool) *Gameboy {
+	gb := New(Config{
+		RomFilename:        "testdata/blargg/cpu_instrs/cpu_instrs.gb",
+		AllowVRAMAccess:    allowVRAMAccess,
+		DisableVideoOutput: true,
+		DisableAudioOutput: true,
+	})
+	gb.mapper.Write(0x8000, 0x12)
+	gb.mapper.Write(0xfe00, 0x34)
+	return gb
+}
+
+// runUntilMode runs the PPU until STAT reports the given mode
+func runUntilMode(gb *Gameboy, mode uint8) {
+	for gb.ppu.ReadSTAT()&0x03 != mode {
+		gb.ppu.EndMachineCycle()
+	}
+}
+
+func TestVRAMAccessBlocking(t *testing.T) {
+	gb := newAccessTest(false)
+	runUntilMode(gb, 2)
+	if gb.mapper.Read(0x8000) != 0x12 || gb.mapper.Read(0xfe00) != 0xff {
+		t.Errorf("mode 2: expected VRAM 0x12 and OAM 0xff but read 0x%02x and 0x%02x", gb.mapper.Read(0x8000), gb.mapper.Read(0xfe00))
+	}
+	gb.mapper.Write(0xfe00, 0x56)
+	runUntilMode(gb, 3)
+	if gb.mapper.Read(0x8000) != 0xff || gb.mapper.Read(0xfe00) != 0xff {
+		t.Errorf("mode 3: expected VRAM and OAM 0xff but read 0x%02x and 0x%02x", gb.mapper.Read(0x8000), gb.mapper.Read(0xfe00))
+	}
+	gb.mapper.Write(0x8000, 0x78)
+	runUntilMode(gb, 0)
+	if gb.mapper.Read(0x8000) != 0x12 || gb.mapper.Read(0xfe00) != 0x34 {
+		t.Errorf("mode 0: expected writes to be dropped but read 0x%02x and 0x%02x", gb.mapper.Read(0x8000), gb.mapper.Read(0xfe00))
+	}
+}
+
+func TestAllowVRAMAccess(t *testing.T) {
+	gb := newAccessTest(true)
+	runUntilMode(gb, 3)
+	if gb.mapper.Read(0x8000) != 0x12 || gb.mapper.Read(0xfe00) != 0x34 {
+		t.Errorf("expected VRAM 0x12 and OAM 0x34 but read 0x%02x and 0x%02x", gb.mapper.Read(0x8000), gb.mapper.Read(0xfe00))
+	}
+	gb.mapper.Write(0x8000, 0x56)
+	if gb.mapper.Read(0x8000) != 0x56 {
+		t.Errorf("expected VRAM 0x56 but read 0x%02x", gb.mapper.Read(0x8000))
+	}
+}
+
+func TestDMAFromVRAMDuringPixelTransfer(t *testing.T) {
+	gb := newAccessTest(false)
+	runUntilMode(gb, 3)
+
+	// OAM DMA copies the real tile data even though the CPU can't read it in mode 3
+	gb.mapper.Write(0xff46, 0x80)
+	for i := 0; i < 162; i++ {
+		gb.mapper.EndMachineCycle()
+	}
+	runUntilMode(gb, 0)
+	if gb.mapper.Read(0xfe00) != 0x12 {
+		t.Errorf("expected OAM DMA to copy 0x12 from VRAM but read 0x%02x", gb.mapper.Read(0xfe00))
+	}
+}
@@ -0,0 +1,88 @@
+package wav
+
+import (
+	"bufio"
+	"encoding/binary"
+	"os"
+)
+
+// Writer writes 16-bit PCM samples to a WAV file. The sizes in the header aren't known until the
+// end so they're filled in when the file is closed.
+type Writer struct {
+	f          *os.File
+	w          *bufio.Writer
+	sampleRate int
+	channels   int
+	frames     uint32
+}
+
+// headerSize is the size of the RIFF header, the format chunk and the data chunk header
+const headerSize = 44
+
+// Create starts a WAV file with the given sample rate and number of channels
+func Create(filename string, sampleRate, channels int) (*Writer, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{
+		f:          f,
+		w:          bufio.NewWriter(f),
+		sampleRate: sampleRate,
+		channels:   channels,
+	}
+	w.w.Write(header(sampleRate, channels, 0))
+	return w, nil
+}
+
+// header returns the WAV header for the given amount of sample data
+func header(sampleRate, channels int, dataSize uint32) []byte {
+	h := make([]byte, headerSize)
+	copy(h[0:], "RIFF")
+	binary.LittleEndian.PutUint32(h[4:], headerSize-8+dataSize)
+	copy(h[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(h[16:], 16)
+	binary.LittleEndian.PutUint16(h[20:], 1) // PCM
+	binary.LittleEndian.PutUint16(h[22:], uint16(channels))
+	binary.LittleEndian.PutUint32(h[24:], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(h[28:], uint32(sampleRate*channels*2))
+	binary.LittleEndian.PutUint16(h[32:], uint16(channels*2))
+	binary.LittleEndian.PutUint16(h[34:], 16)
+	copy(h[36:], "data")
+	binary.LittleEndian.PutUint32(h[40:], dataSize)
+	return h
+}
+
+// Write adds one sample for each channel. Samples range from -1 to 1 and are clipped beyond that.
+// Any error writing the file is returned by Close.
+func (w *Writer) Write(samples ...float32) {
+	var b [2]byte
+	for _, sample := range samples {
+		if sample > 1 {
+			sample = 1
+		} else if sample < -1 {
+			sample = -1
+		}
+		binary.LittleEndian.PutUint16(b[:], uint16(int16(sample*32767)))
+		w.w.Write(b[:])
+	}
+	w.frames++
+}
+
+// Close fills in the sizes in the header and closes the file
+func (w *Writer) Close() error {
+	err := w.finish()
+	if closeErr := w.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (w *Writer) finish() error {
+	err := w.w.Flush()
+	if err != nil {
+		return err
+	}
+	_, err = w.f.WriteAt(header(w.sampleRate, w.channels, w.frames*uint32(w.channels)*2), 0)
+	return err
+}
@@ -0,0 +1,59 @@
+package wav
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "test.wav")
+	w, err := Create(filename, 44100, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(0, 1)
+	w.Write(-1, 0.5)
+	w.Write(2, -2)
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != headerSize+12 || string(data[0:4]) != "RIFF" || string(data[8:16]) != "WAVEfmt " || string(data[36:40]) != "data" {
+		t.Fatalf("unexpected WAV file % x", data)
+	}
+	for _, field := range []struct {
+		name     string
+		actual   uint32
+		expected uint32
+	}{
+		{"RIFF size", binary.LittleEndian.Uint32(data[4:]), 48},
+		{"channels", uint32(binary.LittleEndian.Uint16(data[22:])), 2},
+		{"sample rate", binary.LittleEndian.Uint32(data[24:]), 44100},
+		{"byte rate", binary.LittleEndian.Uint32(data[28:]), 176400},
+		{"bits per sample", uint32(binary.LittleEndian.Uint16(data[34:])), 16},
+		{"data size", binary.LittleEndian.Uint32(data[40:]), 12},
+	} {
+		if field.actual != field.expected {
+			t.Errorf("%s: expected %d but found %d", field.name, field.expected, field.actual)
+		}
+	}
+	expected := []int16{0, 32767, -32767, 16383, 32767, -32767}
+	for i, sample := range expected {
+		actual := int16(binary.LittleEndian.Uint16(data[headerSize+i*2:]))
+		if actual != sample {
+			t.Errorf("sample %d: expected %d but found %d", i, sample, actual)
+		}
+	}
+}
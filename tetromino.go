@@ -7,7 +7,9 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime/pprof"
+	"strings"
 	"syscall"
 
 	"github.com/scottyw/tetromino/gameboy"
@@ -20,6 +22,34 @@ func main() {
 	debugCPU := flag.Bool("debugcpu", false, "When true, CPU debugging is enabled")
 	debugLCD := flag.Bool("debuglcd", false, "When true, colour-based LCD debugging is enabled")
 	enableProfiling := flag.Bool("profiling", false, "When true, CPU profiling data is written to 'cpuprofile.pprof'")
+	boot := flag.Bool("boot", false, "When true, the DMG boot ROM runs before the game")
+	bootROM := flag.String("bootrom", "", "Run this boot ROM file before the game e.g. a DMG0, MGB, SGB or CGB boot ROM")
+	sgb := flag.Bool("sgb", false, "When true, Tetromino runs as a Super Game Boy with colour palettes and borders for games that support it")
+	dmg := flag.Bool("dmg", false, "When true, Game Boy Color games that also support the original Game Boy run in DMG mode")
+	rewindMB := flag.Int("rewind-mb", 64, "Megabytes of memory used to hold rewind history (0 disables rewind)")
+	linkListen := flag.String("link-listen", "", "Wait for another Tetromino to connect a link cable to this address e.g. ':5000'")
+	linkConnect := flag.String("link-connect", "", "Connect a link cable to another Tetromino at this address e.g. 'otherhost:5000'")
+	printerDir := flag.String("printer", "", "Plug in a Game Boy Printer which prints to PNG files in this directory")
+	record := flag.String("record", "", "Record button presses to this movie file")
+	play := flag.String("play", "", "Play back button presses from this movie file")
+	allowVRAM := flag.Bool("allow-vram-access", false, "When true, the CPU can access VRAM and OAM while the PPU is drawing, which helps debug games with display glitches")
+	palette := flag.String("palette", "", "Colours for DMG games: grey, dmg, pocket, cgb-brown, cgb-red, cgb-blue, cgb-green, cgb-inverted or 4 hex colours e.g. 'e0f8d0,88c070,346856,081820'")
+	paletteFile := flag.String("palette-file", "", "Read the colours for DMG games from this palette file")
+	screenshotDir := flag.String("screenshot-dir", "", "Write screenshots and recordings started from the keyboard to this directory instead of the current directory")
+	screenshotScale := flag.Int("screenshot-scale", 1, "Enlarge screenshots by this factor")
+	screenshotRaw := flag.Bool("screenshot-raw", false, "When true, screenshots show only the 160x144 LCD without the SGB border or the debug frame")
+	recordVideo := flag.String("record-video", "", "Record every frame to this animated GIF (.gif) or APNG (.png) file")
+	videoScale := flag.Int("video-scale", 1, "Enlarge recorded frames by this factor")
+	recordAudio := flag.String("record-audio", "", "Record the audio to this WAV file, which also works with --fast or --headless")
+	audioStems := flag.Bool("audio-stems", false, "When true, each audio channel is also recorded to its own WAV file e.g. tetris-ch1.wav to tetris-ch4.wav for tetris.wav")
+	rawAudioStems := flag.Bool("raw-audio-stems", false, "When true, audio channels are recorded in mono without the game's panning and volume")
+	sampleRate := flag.Int("sample-rate", 44100, "Audio samples per second, from 8000 to 192000")
+	audioBuffer := flag.Int("audio-buffer", 200, "Audio samples queued for the speakers, where more avoids crackling but adds delay")
+	audioLatency := flag.Duration("audio-latency", 0, "Latency requested from the audio device e.g. '50ms' (0 uses the device's low latency)")
+	volume := flag.Float64("volume", 0.6, "Master volume from 0 to 1")
+	highPass := flag.String("high-pass", "", "Emulate the output capacitor of this hardware: dmg, cgb or off (matches the hardware when empty)")
+	frames := flag.Int("frames", 0, "Stop after running this many frames (0 runs until the window is closed)")
+	headless := flag.Bool("headless", false, "When true, no window is opened or audio played and the emulator exits when movie playback ends or after the number of frames given by --frames")
 	flag.Parse()
 
 	// CPU profiling
@@ -50,11 +80,53 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Fast mode requires audio to be disabled
+	// Battery-backed cart RAM is saved next to the ROM e.g. tetris.gb is saved to tetris.sav
+	save := strings.TrimSuffix(rom, filepath.Ext(rom)) + ".sav"
+
+	// Movie playback starts from the state embedded in the movie and must not overwrite the real save file
+	if *play != "" {
+		save = ""
+	}
+
+	// Running headless only makes sense when something will stop the emulator
+	if *headless && *play == "" && *frames == 0 {
+		fmt.Println("Headless mode requires a movie to play or a number of frames to run")
+		os.Exit(1)
+	}
+
+	// Fast mode requires audio to be disabled and headless mode runs without speakers too
 	config := gameboy.Config{
 		RomFilename:        rom,
-		DisableVideoOutput: false,
-		DisableAudioOutput: *fast,
+		BootROM:            *boot,
+		BootROMFilename:    *bootROM,
+		ForceDMG:           *dmg,
+		SGB:                *sgb,
+		SaveFilename:       save,
+		RewindBudget:       *rewindMB * 1024 * 1024,
+		LinkListen:         *linkListen,
+		LinkConnect:        *linkConnect,
+		PrinterDirectory:   *printerDir,
+		AllowVRAMAccess:    *allowVRAM,
+		Palette:            *palette,
+		PaletteFile:        *paletteFile,
+		ScreenshotDir:      *screenshotDir,
+		ScreenshotScale:    *screenshotScale,
+		ScreenshotRaw:      *screenshotRaw,
+		RecordVideo:        *recordVideo,
+		VideoScale:         *videoScale,
+		FrameLimit:         *frames,
+		RecordAudio:        *recordAudio,
+		AudioStems:         *audioStems,
+		RawAudioStems:      *rawAudioStems,
+		SampleRate:         *sampleRate,
+		AudioBufferSize:    *audioBuffer,
+		AudioLatency:       *audioLatency,
+		Volume:             volume,
+		HighPassFilter:     *highPass,
+		RecordMovie:        *record,
+		PlayMovie:          *play,
+		DisableVideoOutput: *headless,
+		DisableAudioOutput: *fast || *headless,
 		DebugCPU:           *debugCPU,
 		DebugLCD:           *debugLCD,
 	}